@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+)
+
+// assertExclusive is set by --assert-exclusive
+var assertExclusive bool
+
+func init() {
+	rootCmd.Flags().BoolVar(&assertExclusive, "assert-exclusive", false, "Check that no resource is shared with another worker, exiting 4 if one is; nothing is deleted")
+}
+
+// checkAssertExclusiveFlags rejects flags that would make the check unreliable
+func checkAssertExclusiveFlags() error {
+	if config.SkipDependencyCheck {
+		return fmt.Errorf("--assert-exclusive needs the dependency analysis, it can't be used with --skip-dependency-check")
+	}
+	return nil
+}
+
+// checkExclusive runs the full analysis on a worker and fails unless every
+// resource in its plan is used by that worker alone
+func checkExclusive(ctx context.Context, workerName string) error {
+	// Shared resources must stay in the plan to be reported
+	config.ExclusiveOnly = false
+
+	plan, err := buildPlan(ctx, workerName)
+	if err != nil {
+		return err
+	}
+
+	// Risk levels also flag buckets holding data, only sharing counts here
+	shared := 0
+	for _, resource := range plan.ResourcesToDelete {
+		if resource.IsShared(workerName) {
+			shared++
+		}
+	}
+	if config.JSONOutput {
+		// The plan is the JSON document, don't report the failure again
+		if err := outputJSON(plan, nil, nil); err != nil {
+			return err
+		}
+		if shared > 0 {
+			return exitWith(exitcodes.SharedResources)
+		}
+		return nil
+	}
+
+	if !config.Quiet {
+		fmt.Println(views.RenderExclusivityCheck(plan))
+	}
+
+	if shared > 0 {
+		return exitcodes.WithCode(exitcodes.SharedResources,
+			fmt.Errorf("%s has %d shared resource(s)", workerName, shared))
+	}
+	return nil
+}
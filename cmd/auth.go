@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/configfile"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authCmd = &cobra.Command{
+		Use:   "auth",
+		Short: "Manage the stored Cloudflare API token",
+	}
+
+	authLoginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Prompt for an API token and store it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return newAuthManager().UpdateAPIKey()
+		},
+	}
+
+	authLogoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored API token",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthLogout,
+	}
+
+	authListProfilesCmd = &cobra.Command{
+		Use:   "list-profiles",
+		Short: "List the profiles in the config file",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthListProfiles,
+	}
+
+	authWhoamiCmd = &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the owner and permissions of the API token in use",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthWhoami,
+	}
+)
+
+func init() {
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authListProfilesCmd, authWhoamiCmd)
+	rootCmd.AddCommand(authCmd)
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	authMgr := newAuthManager()
+
+	locations := authMgr.StorageLocations()
+	if len(locations) == 0 {
+		fmt.Println(views.RenderMuted(fmt.Sprintf("No stored credentials at %s", authMgr.CredentialsPath())))
+		return nil
+	}
+
+	for _, location := range locations {
+		fmt.Println(views.RenderProgress(fmt.Sprintf("Removing credentials from %s", location)))
+	}
+	if err := authMgr.DeleteStoredKey(); err != nil {
+		return err
+	}
+
+	fmt.Println(views.RenderSuccess("Logged out"))
+	return nil
+}
+
+func runAuthWhoami(cmd *cobra.Command, args []string) error {
+	apiKey := config.APIKey
+	if apiKey == "" {
+		var err error
+		apiKey, err = newAuthManager().LookupAPIKey()
+		if err != nil {
+			return exitcodes.WithCode(exitcodes.AuthFailure, err)
+		}
+	}
+
+	client, err := api.NewClient(apiKey, "",
+		api.WithContext(cmd.Context()),
+		api.WithRetry(config.RetryMax, config.RetryWaitMax),
+		api.WithLogger(logger))
+	if err != nil {
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	info, err := client.VerifyToken()
+	if err != nil {
+		return exitcodes.WithCode(exitcodes.AuthFailure, err)
+	}
+
+	if config.JSONOutput {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println(views.RenderTokenInfo(info))
+	return nil
+}
+
+func runAuthListProfiles(cmd *cobra.Command, args []string) error {
+	file, err := configfile.Load(configPath, cmd.Flags().Changed("config"))
+	if err != nil {
+		return err
+	}
+	profiles := file.ListProfiles()
+
+	if config.JSONOutput {
+		data, err := json.MarshalIndent(profiles, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(profiles) == 0 {
+		fmt.Println(views.RenderMuted(fmt.Sprintf("No profiles in %s", configPath)))
+		return nil
+	}
+
+	for _, p := range profiles {
+		line := p.Name
+		if p.Default {
+			line = views.RenderHighlight(p.Name) + " (default)"
+		}
+		details := []string{}
+		if p.AccountID != "" {
+			details = append(details, "account "+p.AccountID)
+		}
+		if p.HasAPIToken {
+			details = append(details, "own API token")
+		}
+		if len(details) > 0 {
+			line += " " + views.RenderMuted(strings.Join(details, ", "))
+		}
+		fmt.Println("  • " + line)
+	}
+	return nil
+}
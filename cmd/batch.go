@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattietk/cf-purge-worker/internal/analyzer"
+	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/deleter"
+	"github.com/mattietk/cf-purge-worker/internal/eventlog"
+	"github.com/mattietk/cf-purge-worker/internal/ui/models"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// readWorkersFile reads newline-separated worker names from path, or from
+// stdin when path is "-". Blank lines and lines starting with # are ignored.
+func readWorkersFile(path string) ([]string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open workers file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read workers file: %w", err)
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no worker names found in %s", path)
+	}
+	return names, nil
+}
+
+// isWorkerPattern reports whether a worker name argument is a glob pattern
+func isWorkerPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// purgeBatch runs the analysis and deletion pipeline for every worker listed
+// in the workers file
+func purgeBatch(ctx context.Context, path string) error {
+	// Summary and JSON modes suppress all intermediate human-readable output
+	if config.Summary || config.JSONOutput {
+		config.Quiet = true
+	}
+
+	interactive := !config.Force && !config.AutoYes && !config.DryRun && !config.JSONOutput
+	if interactive && path == "-" {
+		return fmt.Errorf("reading workers from stdin needs --yes, --force, --dry-run or --json, as prompts also read stdin")
+	}
+
+	names, err := readWorkersFile(path)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return purgeWorkers(ctx, client, names, path)
+}
+
+// purgeMatching deletes every worker whose name matches a glob pattern, after
+// previewing the matches and asking for confirmation
+func purgeMatching(ctx context.Context, pattern string) error {
+	if config.Summary || config.JSONOutput {
+		config.Quiet = true
+	}
+
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	names, err := client.MatchWorkers(pattern)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return exitcodes.WithCode(exitcodes.WorkerNotFound, fmt.Errorf("no workers match %s", pattern))
+	}
+
+	interactive := !config.Force && !config.AutoYes && !config.DryRun && !config.JSONOutput
+	if interactive {
+		finalModel, err := tea.NewProgram(models.NewMatchConfirmModel(pattern, names)).Run()
+		if err != nil {
+			return fmt.Errorf("UI error: %w", err)
+		}
+		if !finalModel.(models.MatchConfirmModel).Confirmed {
+			return exitWith(exitcodes.UserCancelled)
+		}
+	} else if !config.Quiet {
+		fmt.Println(views.RenderMatchedWorkers(pattern, names))
+	}
+
+	return purgeWorkers(ctx, client, names, pattern)
+}
+
+// planMatchingRegex shows the deletion plan of every worker whose name
+// matches a --name-regex pattern. Nothing is deleted: a regular expression
+// is too easy to get wrong to delete by.
+func planMatchingRegex(ctx context.Context, pattern string) error {
+	re, err := compileNameRegex(pattern)
+	if err != nil {
+		return err
+	}
+	config.DryRun = true
+	if config.Summary || config.JSONOutput {
+		config.Quiet = true
+	}
+
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	names, err := client.MatchWorkersRegex(re)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return exitcodes.WithCode(exitcodes.WorkerNotFound, fmt.Errorf("no workers match %s", pattern))
+	}
+	if !config.Quiet {
+		fmt.Println(views.RenderMatchedWorkers(pattern, names))
+	}
+
+	return purgeWorkers(ctx, client, names, pattern)
+}
+
+// compileNameRegex parses a --name-regex pattern
+func compileNameRegex(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --name-regex %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// purgeWorkers runs the analysis and deletion pipeline for each named worker
+func purgeWorkers(ctx context.Context, client *api.Client, names []string, source string) error {
+	var allowed []string
+	for _, name := range names {
+		if isProtected(name) {
+			if !config.Quiet {
+				fmt.Println(views.RenderWarning(fmt.Sprintf("Skipping protected worker %s", name)))
+			}
+			continue
+		}
+		allowed = append(allowed, name)
+	}
+	if len(allowed) == 0 {
+		return exitcodes.WithCode(exitcodes.WorkerProtected, fmt.Errorf("every worker from %s is protected", source))
+	}
+	names = allowed
+
+	interactive := !config.Force && !config.AutoYes && !config.DryRun && !config.JSONOutput
+
+	a, err := newAnalyzer(client)
+	if err != nil {
+		return err
+	}
+	d := newDeleter(client)
+
+	if !config.Quiet {
+		fmt.Println(views.RenderHeader())
+		fmt.Println(views.RenderProgress(fmt.Sprintf("Processing %d worker(s) from %s", len(names), source)))
+	}
+
+	var batch *types.BatchDeletionResult
+	if interactive {
+		batch, err = purgeBatchInteractive(ctx, client, a, d, names)
+		if err != nil {
+			return err
+		}
+	} else {
+		batch = &types.BatchDeletionResult{}
+		for i, name := range names {
+			outcome := purgeWorker(client, a, d, name)
+			batch.Add(outcome)
+
+			if config.FailFast && outcome.Status == types.OutcomeFailed {
+				skipRemaining(batch, names[i+1:])
+				break
+			}
+		}
+	}
+
+	if err := outputBatch(batch, !interactive); err != nil {
+		return err
+	}
+
+	if code := exitcodes.ForStatus(batch.WorstStatus()); code != exitcodes.Success {
+		return exitWith(code)
+	}
+	return nil
+}
+
+// purgeBatchInteractive fetches every worker and steps through them in the TUI
+func purgeBatchInteractive(ctx context.Context, client *api.Client, a *analyzer.Analyzer, d *deleter.Deleter, names []string) (*types.BatchDeletionResult, error) {
+	batch := &types.BatchDeletionResult{}
+
+	var workerModels []models.Model
+	for i, name := range names {
+		worker, err := getWorker(client, name)
+		if err == nil {
+			err = checkRequiredTag(worker)
+		}
+		if err != nil {
+			batch.Add(types.WorkerOutcome{WorkerName: name, Status: types.OutcomeFailed, Err: err})
+			if config.FailFast {
+				skipRemaining(batch, names[i+1:])
+				return batch, nil
+			}
+			continue
+		}
+		attachUsageMetrics(client, worker)
+		workerModels = append(workerModels, models.NewModelWithAnalysis(worker, a, &config, d).WithContext(ctx))
+	}
+
+	bulk := models.NewBulkModel(workerModels).WithFailFast(config.FailFast)
+	finalModel, err := tea.NewProgram(bulk).Run()
+	if err != nil {
+		return nil, fmt.Errorf("UI error: %w", err)
+	}
+
+	for _, outcome := range finalModel.(models.BulkModel).Batch().Outcomes {
+		if outcome.Plan != nil {
+			writeAudit(outcome.WorkerName, outcome.Plan, nil)
+		}
+		if outcome.Result != nil {
+			writeAudit(outcome.WorkerName, nil, outcome.Result)
+			sendWebhook(ctx, outcome.Result)
+			eventlog.Result(logger, outcome.WorkerName, outcome.Result)
+		}
+		batch.Add(outcome)
+	}
+	return batch, nil
+}
+
+// purgeWorker runs the non-interactive pipeline for one worker of a batch
+func purgeWorker(client *api.Client, a *analyzer.Analyzer, d *deleter.Deleter, name string) types.WorkerOutcome {
+	outcome := types.WorkerOutcome{WorkerName: name}
+
+	if !config.Quiet {
+		fmt.Println(views.RenderProgress(fmt.Sprintf("Analyzing worker: %s", name)))
+	}
+
+	worker, err := getWorker(client, name)
+	if err == nil {
+		err = checkRequiredTag(worker)
+	}
+	if err != nil {
+		outcome.Status = types.OutcomeFailed
+		outcome.Err = err
+		return outcome
+	}
+	attachUsageMetrics(client, worker)
+
+	var resources []types.ResourceUsage
+	if config.SkipDependencyCheck {
+		resources, err = a.GetTargetWorkerResources(worker)
+	} else {
+		resources, err = a.AnalyzeDependencies(worker)
+	}
+	if err != nil {
+		outcome.Status = types.OutcomeFailed
+		outcome.Err = fmt.Errorf("failed to analyze dependencies: %w", err)
+		return outcome
+	}
+
+	plan := a.CreateDeletionPlan(worker, resources, planOptions())
+	outcome.Plan = plan
+	writeAudit(name, plan, nil)
+
+	// Without --yes or --force, JSON mode only plans, like a single worker run
+	if config.DryRun || (!config.Force && !config.AutoYes) {
+		if !config.Quiet {
+			fmt.Println(views.RenderDeletionPlan(plan))
+		}
+		outcome.Status = types.OutcomePlanned
+		return outcome
+	}
+
+	plan.DeleteShared = !config.ExclusiveOnly
+
+	result, err := d.ExecuteWithRollback(plan, deletionProgress(plan))
+	if err == nil && config.WaitForPropagation {
+		awaitPropagation(d, result, name)
+	}
+	outcome.Result = result
+	if result != nil {
+		writeAudit(name, nil, result)
+		sendWebhook(client.Context(), result)
+		eventlog.Result(logger, name, result)
+	}
+	switch {
+	case err != nil:
+		outcome.Status = types.OutcomeFailed
+		outcome.Err = err
+	case result.SkippedReason != "":
+		outcome.Status = types.OutcomeSkipped
+	case result.Success:
+		outcome.Status = types.OutcomeDeleted
+	default:
+		outcome.Status = types.OutcomeFailed
+	}
+
+	if !config.Quiet && result != nil {
+		fmt.Println(views.RenderDeletionResult(result))
+	}
+	return outcome
+}
+
+// skipRemaining records the workers left unprocessed by --fail-fast
+func skipRemaining(batch *types.BatchDeletionResult, names []string) {
+	for _, name := range names {
+		batch.Add(types.WorkerOutcome{
+			WorkerName: name,
+			Status:     types.OutcomeAborted,
+			Err:        types.ErrSkippedAfterFailure,
+		})
+	}
+}
+
+// outputBatch prints the batch outcome as summary lines, a JSON array or, when
+// the TUI hasn't already shown it, the summary box
+func outputBatch(batch *types.BatchDeletionResult, showSummary bool) error {
+	switch {
+	case config.Summary:
+		for _, outcome := range batch.Outcomes {
+			if err := printSummary(outcomeSummary(outcome)); err != nil {
+				return err
+			}
+		}
+	case config.JSONOutput:
+		data, err := json.MarshalIndent(batch, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+	case showSummary:
+		fmt.Println(views.RenderBulkSummary(batch.Outcomes))
+	}
+	return nil
+}
+
+// outcomeSummary converts a batch outcome into a summary line
+func outcomeSummary(outcome types.WorkerOutcome) summary {
+	s := summary{Worker: outcome.WorkerName, Status: string(outcome.Status)}
+
+	switch {
+	case outcome.Result != nil:
+		s.Resources = len(outcome.Result.ResourcesDeleted)
+		s.Errors = len(outcome.Result.Errors)
+	case outcome.Status == types.OutcomePlanned && outcome.Plan != nil:
+		s.Resources = outcome.Plan.Summary().TotalResources
+	}
+	if outcome.Err != nil && s.Errors == 0 && outcome.Status == types.OutcomeFailed {
+		s.Errors = 1
+	}
+	return s
+}
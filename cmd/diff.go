@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/diff"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// diffFile is set by --diff to a plan saved with --json
+var diffFile string
+
+func init() {
+	rootCmd.Flags().StringVar(&diffFile, "diff", "", "Compare the plan with one saved by --json and show what changed; nothing is deleted")
+	planCmd.Flags().StringVar(&diffFile, "diff", "", "Compare the plan with one saved by --json and show what changed")
+}
+
+// diffOutput is the JSON document printed by --diff --json
+type diffOutput struct {
+	diff.PlanDiff
+	Unified string `json:"unified"`
+}
+
+// loadPlanFile reads a plan saved by --json. Both the full JSON output and a
+// bare plan are accepted.
+func loadPlanFile(path string) (*types.DeletionPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var wrapped jsonOutput
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %w", path, err)
+	}
+	if wrapped.Plan != nil {
+		return wrapped.Plan, nil
+	}
+
+	var plan types.DeletionPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %w", path, err)
+	}
+	if plan.Worker.Name == "" {
+		return nil, fmt.Errorf("%s does not contain a deletion plan", path)
+	}
+	return &plan, nil
+}
+
+// showPlanDiff computes the worker's plan and prints how it differs from the
+// plan saved in diffFile
+func showPlanDiff(ctx context.Context, workerName string) error {
+	previous, err := loadPlanFile(diffFile)
+	if err != nil {
+		return err
+	}
+	if previous.Worker.Name != workerName {
+		return fmt.Errorf("%s is a plan for %s, not %s", diffFile, previous.Worker.Name, workerName)
+	}
+
+	plan, err := buildPlan(ctx, workerName)
+	if err != nil {
+		return err
+	}
+
+	d := diff.DiffPlans(previous, plan)
+	if config.JSONOutput {
+		data, err := json.MarshalIndent(diffOutput{PlanDiff: d, Unified: d.Unified()}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println(views.RenderPlanDiff(d))
+	return nil
+}
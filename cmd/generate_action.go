@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	actionOutput      string
+	generateActionCmd = &cobra.Command{
+		Use:   "generate-action",
+		Short: "Write a GitHub Actions workflow that purges a worker",
+		Long: `Write a GitHub Actions workflow that purges the worker named when the
+workflow is run. The workflow reads CLOUDFLARE_API_TOKEN and
+CLOUDFLARE_ACCOUNT_ID from the repository secrets, and only deletes resources
+no other worker uses. It starts as a dry run unless told otherwise.
+
+  cf-purge-worker generate-action -o .github/workflows/purge-worker.yml`,
+		Args: cobra.NoArgs,
+		RunE: runGenerateAction,
+	}
+)
+
+// actionFlags are the CI-safe flags the workflow passes on every run: no
+// prompts, nothing shared with other workers, no release check
+var actionFlags = []string{"yes", "exclusive-only", "no-update-check"}
+
+func init() {
+	generateActionCmd.Flags().StringVarP(&actionOutput, "output", "o", "", "Write the workflow to this file instead of stdout")
+	rootCmd.AddCommand(generateActionCmd)
+}
+
+func runGenerateAction(cmd *cobra.Command, args []string) error {
+	workflow, err := actionWorkflow()
+	if err != nil {
+		return err
+	}
+
+	if actionOutput == "" {
+		fmt.Print(workflow)
+		return nil
+	}
+	if err := os.WriteFile(actionOutput, []byte(workflow), 0o644); err != nil {
+		return fmt.Errorf("failed to write workflow: %w", err)
+	}
+	if !config.Quiet {
+		fmt.Printf("Wrote %s\n", actionOutput)
+	}
+	return nil
+}
+
+// actionWorkflow renders the workflow YAML. Flags are looked up on the root
+// command so a renamed flag fails here rather than in someone's CI.
+func actionWorkflow() (string, error) {
+	var flags []string
+	for _, name := range actionFlags {
+		if rootCmd.Flags().Lookup(name) == nil {
+			return "", fmt.Errorf("workflow flag --%s no longer exists", name)
+		}
+		flags = append(flags, "--"+name)
+	}
+	if rootCmd.Flags().Lookup("dry-run") == nil {
+		return "", fmt.Errorf("workflow flag --dry-run no longer exists")
+	}
+
+	// The worker name goes through the environment so an input can't inject
+	// shell commands into the run step
+	return fmt.Sprintf(`name: Purge Cloudflare Worker
+
+on:
+  workflow_dispatch:
+    inputs:
+      worker:
+        description: Name of the worker to delete
+        required: true
+        type: string
+      dry_run:
+        description: Show the deletion plan without deleting anything
+        required: false
+        type: boolean
+        default: true
+
+jobs:
+  purge:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-go@v5
+        with:
+          go-version: stable
+
+      - name: Install cf-purge-worker
+        run: go install github.com/mattietk/cf-purge-worker@v%s
+
+      - name: Purge worker
+        env:
+          CLOUDFLARE_API_TOKEN: ${{ secrets.CLOUDFLARE_API_TOKEN }}
+          CLOUDFLARE_ACCOUNT_ID: ${{ secrets.CLOUDFLARE_ACCOUNT_ID }}
+          WORKER_NAME: ${{ inputs.worker }}
+        run: cf-purge-worker "$WORKER_NAME" %s ${{ inputs.dry_run && '--dry-run' || '' }}
+`, rootCmd.Version, strings.Join(flags, " ")), nil
+}
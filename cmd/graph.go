@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattietk/cf-purge-worker/internal/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat string
+	graphCmd    = &cobra.Command{
+		Use:   "graph <worker-name>",
+		Short: "Show the worker's resources and the other workers bound to them",
+		Long: `Show a dependency graph of the worker's resources and every other worker
+bound to them. The default ASCII tree is meant for the terminal; --format dot
+prints Graphviz DOT, for example:
+
+  cf-purge-worker graph my-worker --format dot | dot -Tsvg > graph.svg`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGraph,
+	}
+)
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "ascii", "Output format: ascii or dot")
+	graphCmd.Flags().IntVar(&config.AnalysisConcurrency, "analysis-concurrency", 10, "Number of workers fetched in parallel during dependency analysis")
+	graphCmd.Flags().BoolVar(&config.NoEnrichment, "no-enrichment", false, "Skip resource name lookups and show resource IDs instead")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	if graphFormat != "ascii" && graphFormat != "dot" {
+		return fmt.Errorf("invalid --format %q: use ascii or dot", graphFormat)
+	}
+
+	client, err := newClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	worker, err := getWorker(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	a, err := newAnalyzer(client, analyzer.WithWorkerCache(worker))
+	if err != nil {
+		return err
+	}
+
+	graph, err := a.GetResourceGraph(worker)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case config.JSONOutput:
+		data, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+	case graphFormat == "dot":
+		fmt.Print(graph.RenderDOT())
+	default:
+		fmt.Print(graph.RenderASCII())
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <worker-name>",
+	Short: "Show a worker's bindings without planning any deletion",
+	Long: `Show a worker's details and every binding it has, grouped by type, with
+the names of the KV namespaces and D1 databases they point at. No other
+worker is scanned and nothing is deleted. --verbose adds the IDs behind each
+binding; --json prints the worker as JSON.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	inspectCmd.Flags().BoolVarP(&config.Verbose, "verbose", "v", false, "Also show the IDs and names behind each binding")
+	inspectCmd.Flags().BoolVar(&config.NoEnrichment, "no-enrichment", false, "Skip resource name lookups and show resource IDs instead")
+	rootCmd.AddCommand(inspectCmd)
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	client, err := newClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	worker, err := getWorker(client, args[0])
+	if err != nil {
+		return err
+	}
+	if !config.NoEnrichment {
+		nameBindings(client, worker.Bindings)
+	}
+	attachUsageMetrics(client, worker)
+
+	if config.JSONOutput {
+		data, err := json.MarshalIndent(worker, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println(views.RenderWorkerBindings(worker, config.Verbose))
+	return nil
+}
+
+// nameBindings fills in the KV namespace titles and D1 database names of
+// bindings, listing each resource type once. A failed listing leaves the
+// names empty, the IDs are shown instead.
+func nameBindings(client *api.Client, bindings []types.Binding) {
+	var kvTitles, d1Names map[string]string
+	for i := range bindings {
+		b := &bindings[i]
+		switch {
+		case b.Type == types.BindingTypeKV && b.NamespaceName == "":
+			if kvTitles == nil {
+				kvTitles = make(map[string]string)
+				if namespaces, err := client.ListAllKVNamespaces(); err == nil {
+					for _, ns := range namespaces {
+						kvTitles[ns.ID] = ns.Title
+					}
+				}
+			}
+			b.NamespaceName = kvTitles[b.NamespaceID]
+		case b.Type == types.BindingTypeD1 && b.DatabaseName == "":
+			if d1Names == nil {
+				d1Names = make(map[string]string)
+				if databases, err := client.ListAllD1Databases(); err == nil {
+					for _, db := range databases {
+						d1Names[db.UUID] = db.Name
+					}
+				}
+			}
+			b.DatabaseName = d1Names[b.DatabaseID]
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// listConcurrency is the number of workers whose bindings are fetched in parallel
+const listConcurrency = 10
+
+var (
+	listSort string
+	listTag  string
+	listCmd  = &cobra.Command{
+		Use:   "list",
+		Short: "List all workers in the account with their binding counts",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+)
+
+func init() {
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "Sort order: name or modified (most recent first)")
+	listCmd.Flags().StringVar(&listTag, "tag", "", "Only list workers that carry this tag")
+	listCmd.Flags().StringVar(&config.NameRegex, "name-regex", "", "Only list workers whose names match this regular expression")
+	rootCmd.AddCommand(listCmd)
+}
+
+// workerListEntry is a row of the list output
+type workerListEntry struct {
+	Name         string    `json:"name"`
+	CreatedOn    time.Time `json:"created_on"`
+	ModifiedOn   time.Time `json:"modified_on"`
+	BindingCount int       `json:"binding_count"`
+	Tags         []string  `json:"tags,omitempty"`
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	if listSort != "name" && listSort != "modified" {
+		return fmt.Errorf("invalid --sort value %q (expected name or modified)", listSort)
+	}
+
+	var nameRe *regexp.Regexp
+	if config.NameRegex != "" {
+		var err error
+		if nameRe, err = compileNameRegex(config.NameRegex); err != nil {
+			return err
+		}
+	}
+
+	client, err := newClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	workers, err := client.ListWorkers()
+	if err != nil {
+		return err
+	}
+
+	// Filter before fetching bindings, so only matching workers cost requests
+	if nameRe != nil {
+		workers = slices.DeleteFunc(workers, func(w types.WorkerInfo) bool {
+			return !nameRe.MatchString(w.Name)
+		})
+	}
+
+	// Fetch bindings and tags in parallel; a worker we can't read just shows
+	// no bindings
+	var g errgroup.Group
+	g.SetLimit(listConcurrency)
+	for i := range workers {
+		i := i
+		g.Go(func() error {
+			if settings, err := client.GetWorkerSettings(workers[i].Name); err == nil {
+				workers[i].Bindings = settings.Bindings
+				workers[i].Tags = settings.Tags
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if listTag != "" {
+		workers = slices.DeleteFunc(workers, func(w types.WorkerInfo) bool {
+			return !slices.Contains(w.Tags, listTag)
+		})
+	}
+
+	sortWorkers(workers, listSort)
+
+	if config.JSONOutput {
+		entries := make([]workerListEntry, 0, len(workers))
+		for _, w := range workers {
+			entries = append(entries, workerListEntry{
+				Name:         w.Name,
+				CreatedOn:    w.CreatedOn,
+				ModifiedOn:   w.ModifiedOn,
+				BindingCount: len(w.Bindings),
+				Tags:         w.Tags,
+			})
+		}
+
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println(views.RenderWorkerList(workers))
+	return nil
+}
+
+// sortWorkers orders workers by name or by last modification (newest first)
+func sortWorkers(workers []types.WorkerInfo, order string) {
+	sort.Slice(workers, func(i, j int) bool {
+		if order == "modified" {
+			return workers[i].ModifiedOn.After(workers[j].ModifiedOn)
+		}
+		return workers[i].Name < workers[j].Name
+	})
+}
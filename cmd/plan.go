@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattietk/cf-purge-worker/internal/analyzer"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan <worker-name>",
+	Short: "Print the deletion plan for a worker without prompting or deleting",
+	Long: `Print the deletion plan for a worker, including the risk level of each
+resource, without prompting and without making any changes. Useful in CI to
+review what would be deleted before approving a deletion step.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlan,
+}
+
+func init() {
+	planCmd.Flags().BoolVar(&config.ExclusiveOnly, "exclusive-only", false, "Only plan deletion of resources not shared with other workers")
+	planCmd.Flags().BoolVar(&config.SkipDependencyCheck, "skip-dependency-check", false, "Skip checking if other workers use the same resources")
+	planCmd.Flags().IntVar(&config.AnalysisConcurrency, "analysis-concurrency", 10, "Number of workers fetched in parallel during dependency analysis")
+	planCmd.Flags().IntVar(&config.MaxWorkers, "max-workers", 0, "Scan at most this many workers for dependencies, most recently modified first")
+	planCmd.Flags().BoolVar(&config.NoEnrichment, "no-enrichment", false, "Skip resource name lookups and show resource IDs instead")
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	err := printPlan(cmd.Context(), args[0])
+	if err != nil && config.JSONOutput {
+		_ = outputJSON(nil, nil, err)
+		return reported(err)
+	}
+	return err
+}
+
+// printPlan prints the worker's plan, or its changes since --diff's plan
+func printPlan(ctx context.Context, workerName string) error {
+	if diffFile != "" {
+		return showPlanDiff(ctx, workerName)
+	}
+
+	plan, err := buildPlan(ctx, workerName)
+	if err != nil {
+		return err
+	}
+
+	if config.JSONOutput {
+		return outputJSON(plan, nil, nil)
+	}
+
+	fmt.Println(views.RenderDeletionPlan(plan))
+	return nil
+}
+
+// buildPlan analyzes a worker and creates its deletion plan without any prompts
+func buildPlan(ctx context.Context, workerName string) (*types.DeletionPlan, error) {
+	client, err := newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	worker, err := getWorker(client, workerName)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := newAnalyzer(client, analyzer.WithWorkerCache(worker))
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []types.ResourceUsage
+	if config.SkipDependencyCheck {
+		resources, err = a.GetTargetWorkerResources(worker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get worker resources: %w", err)
+		}
+	} else {
+		resources, err = a.AnalyzeDependencies(worker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze dependencies: %w", err)
+		}
+	}
+
+	return a.CreateDeletionPlan(worker, resources, planOptions()), nil
+}
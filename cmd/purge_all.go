@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+	"github.com/spf13/cobra"
+)
+
+// purgeAll is set by the hidden --purge-all flag
+var purgeAll bool
+
+func init() {
+	rootCmd.Flags().BoolVar(&purgeAll, "purge-all", false, "Delete every worker in the account and its exclusive resources (requires --yes --force)")
+	_ = rootCmd.Flags().MarkHidden("purge-all")
+}
+
+// checkPurgeAllFlags makes sure --yes and --force were both given on the
+// command line, not just picked up from the config file
+func checkPurgeAllFlags(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("yes") || !cmd.Flags().Changed("force") {
+		return fmt.Errorf("--purge-all requires both --yes and --force on the command line")
+	}
+	return nil
+}
+
+// purgeAccount deletes every worker in the account together with the
+// resources exclusive to it, after the user types the account ID to confirm
+func purgeAccount(ctx context.Context) error {
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	workers, err := client.ListWorkers()
+	if err != nil {
+		return err
+	}
+	if len(workers) == 0 {
+		fmt.Println(views.RenderMuted("No workers found"))
+		return nil
+	}
+
+	names := make([]string, 0, len(workers))
+	for _, w := range workers {
+		names = append(names, w.Name)
+	}
+
+	fmt.Println(views.RenderHeader())
+	fmt.Println(views.RenderError("⚠️  PURGE ALL: every worker in this account will be deleted"))
+	fmt.Println()
+	fmt.Printf("Account: %s\n", views.RenderHighlight(config.AccountID))
+	fmt.Printf("Workers: %s\n", views.RenderHighlight(fmt.Sprintf("%d", len(names))))
+	fmt.Println()
+	fmt.Println("Resources shared between workers are kept; exclusive resources are deleted.")
+	fmt.Printf("Type the account ID to confirm: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	if strings.TrimSpace(response) != config.AccountID {
+		fmt.Println(views.RenderWarning("Account ID did not match, nothing was deleted"))
+		return exitWith(exitcodes.UserCancelled)
+	}
+
+	// Only exclusive resources are deleted alongside each worker
+	config.ExclusiveOnly = true
+
+	return purgeWorkers(ctx, client, names, fmt.Sprintf("account %s", config.AccountID))
+}
@@ -1,22 +1,68 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mattietk/cf-purge-worker/internal/analyzer"
 	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/audit"
 	"github.com/mattietk/cf-purge-worker/internal/auth"
+	"github.com/mattietk/cf-purge-worker/internal/configfile"
 	"github.com/mattietk/cf-purge-worker/internal/deleter"
+	"github.com/mattietk/cf-purge-worker/internal/eventlog"
 	"github.com/mattietk/cf-purge-worker/internal/ui/models"
+	"github.com/mattietk/cf-purge-worker/internal/ui/styles"
 	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/internal/webhook"
+	apperrors "github.com/mattietk/cf-purge-worker/pkg/errors"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+	"github.com/mattietk/cf-purge-worker/pkg/report"
 	"github.com/mattietk/cf-purge-worker/pkg/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
+// accountIDEnvVar selects the account when --account-id isn't given
+const accountIDEnvVar = "CLOUDFLARE_ACCOUNT_ID"
+
+// recentErrorWindow is how far back verbose mode looks for worker errors
+const recentErrorWindow = 24 * time.Hour
+
+// usageMetricsWindow is how far back a worker's traffic is counted
+const usageMetricsWindow = 30 * 24 * time.Hour
+
 var (
-	config types.Config
+	config     types.Config
+	configPath string
+	profile    string
+	noColor    bool
+	logFile    string
+	// lastResult is the result of the run's single worker deletion, returned
+	// by ExecuteWithResult
+	lastResult *types.DeletionResult
+	// lastCommand is the command the last run executed
+	lastCommand *cobra.Command
+	// logger writes --log-file entries, discarding them when no file is set
+	logger   = slog.New(slog.DiscardHandler)
+	closeLog func() error
+	// skipTypes holds the raw --skip-type values until they are parsed
+	skipTypes []string
+	// cancelTimeout releases the --timeout context once the command finishes
+	cancelTimeout context.CancelFunc
+	// activeProfile is the profile in use, --profile or the config file's
+	// default_profile
+	activeProfile string
 	rootCmd = &cobra.Command{
 		Use:   "cf-purge-worker [worker-name]",
 		Short: "Safely delete Cloudflare Workers and their resources",
@@ -24,61 +70,548 @@ var (
 and their associated resources (KV namespaces, R2 buckets, D1 databases, etc.)
 while preventing accidental deletion of shared resources.`,
 		Version: "0.1.0",
-		Args:    cobra.ExactArgs(1),
-		RunE:    run,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// A workers file, --name-regex or --purge-all replaces the worker
+			// name argument
+			if config.WorkersFile != "" || config.NameRegex != "" || purgeAll {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: run,
+		// Execute reports errors itself
+		SilenceErrors: true,
 	}
 )
 
 func init() {
-	rootCmd.Flags().StringVar(&config.AccountID, "account-id", "", "Cloudflare account ID")
+	rootCmd.PersistentFlags().StringVar(&config.AccountID, "account-id", "", "Cloudflare account ID")
+	rootCmd.PersistentFlags().BoolVar(&config.NoKeychain, "no-keychain", false, "Store and read the API token only from the credentials file, not the OS keychain")
+	rootCmd.PersistentFlags().StringVar(&config.APIKey, "api-token", "", "Cloudflare API token (overrides environment and stored credentials)")
 	rootCmd.Flags().BoolVarP(&config.DryRun, "dry-run", "d", false, "Show deletion plan without executing")
 	rootCmd.Flags().BoolVarP(&config.Force, "force", "f", false, "Skip confirmation prompts (dangerous)")
 	rootCmd.Flags().BoolVar(&config.ExclusiveOnly, "exclusive-only", false, "Only delete resources not shared with other workers")
+	rootCmd.Flags().BoolVar(&config.ForceDeleteShared, "force-delete-shared", false, "Delete shared resources without asking, still confirming the deletion itself")
 	rootCmd.Flags().BoolVarP(&config.AutoYes, "yes", "y", false, "Answer yes to all prompts")
 	rootCmd.Flags().BoolVarP(&config.Verbose, "verbose", "v", false, "Verbose logging")
 	rootCmd.Flags().BoolVarP(&config.Quiet, "quiet", "q", false, "Minimal output")
-	rootCmd.Flags().BoolVar(&config.JSONOutput, "json", false, "Output results in JSON format")
+	rootCmd.PersistentFlags().BoolVar(&config.JSONOutput, "json", false, "Output results in JSON format")
+	rootCmd.Flags().BoolVar(&config.Summary, "summary", false, "Print only a single summary line with the outcome")
+	rootCmd.Flags().BoolVarP(&config.InteractiveSelect, "interactive-select", "i", false, "Choose which resources in the plan to delete from a checklist")
 	rootCmd.Flags().BoolVar(&config.SkipDependencyCheck, "skip-dependency-check", false, "Skip checking if other workers use the same resources")
+	rootCmd.Flags().StringArrayVar(&config.ProtectedWorkers, "protect", nil, "Worker name or glob pattern that must never be deleted (repeatable)")
+	rootCmd.Flags().StringVar(&config.RequireTag, "require-tag", "", "Only delete workers that carry this tag (e.g. deprecated)")
+	rootCmd.Flags().StringVar(&config.NameRegex, "name-regex", "", "Show the deletion plan of every worker whose name matches this regular expression")
+	rootCmd.Flags().StringVar(&config.WorkersFile, "workers-file", "", "Delete every worker listed in this file, one per line (- for stdin)")
+	rootCmd.PersistentFlags().BoolVar(&config.DeleteRoutes, "delete-routes", false, "Also delete the zone routes that point at the worker")
+	rootCmd.PersistentFlags().BoolVar(&config.SkipWorkerDeletion, "skip-worker-deletion", false, "Keep the worker script and delete only its resources")
+	rootCmd.PersistentFlags().BoolVar(&config.DeleteSecrets, "delete-secrets", false, "With --skip-worker-deletion, also delete the kept worker's secrets")
+	rootCmd.Flags().BoolVar(&config.WaitForPropagation, "wait-for-propagation", false, "After deleting, wait until the API no longer serves the worker")
+	rootCmd.Flags().DurationVar(&config.PropagationTimeout, "propagation-timeout", 30*time.Second, "Longest wait for --wait-for-propagation")
+	rootCmd.Flags().StringVar(&config.ManifestFile, "save-manifest", "", "Record deleted resources in this JSON file so `undo` can re-create them")
+	rootCmd.Flags().StringVar(&config.SnapshotDir, "create-snapshot", "", "Save KV and D1 data to files in this directory before deleting it")
+	rootCmd.Flags().StringVar(&config.OutputFile, "output-file", "", "Append the deletion plan and result as JSON to this audit file")
+	rootCmd.Flags().StringVar(&config.ReportFile, "report", "", "Write a self-contained HTML report of the deletion to this file")
+	rootCmd.Flags().StringVar(&config.Webhook, "webhook", "", "POST the deletion result as JSON to this URL")
+	rootCmd.Flags().StringVar(&config.WebhookSecret, "webhook-secret", "", "Secret sent in the webhook's "+webhook.SecretHeader+" header (default $"+webhook.SecretEnvVar+")")
+	rootCmd.Flags().BoolVar(&config.FailFast, "fail-fast", false, "Stop a --workers-file batch at the first failed worker")
+	rootCmd.Flags().IntVar(&config.AnalysisConcurrency, "analysis-concurrency", 10, "Number of workers fetched in parallel during dependency analysis")
+	rootCmd.PersistentFlags().IntVar(&config.DeletionConcurrency, "concurrency", 1, "Number of resources deleted in parallel once the worker script is gone")
+	rootCmd.Flags().IntVar(&config.MaxWorkers, "max-workers", 0, "Scan at most this many workers for dependencies, most recently modified first")
+	rootCmd.Flags().BoolVar(&config.NoEnrichment, "no-enrichment", false, "Skip resource name lookups and show resource IDs instead")
+	rootCmd.PersistentFlags().StringArrayVar(&config.Include, "include", nil, "Only delete resources of this type: kv, r2, d1, queue, vectorize, hyperdrive, ... (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&config.TagFilter, "tag-filter", "", "Only delete KV, R2 and D1 resources carrying this tag")
+	rootCmd.PersistentFlags().StringVar(&config.RegionFilter, "region-filter", "", "Only delete R2 buckets in this jurisdiction (eu, fedramp or default), keep the others")
+	rootCmd.PersistentFlags().StringArrayVar(&skipTypes, "skip-type", nil, "Never delete resources of this type, shared or not: kv, r2, d1, ... (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&config.SkipEmptyResources, "skip-empty-resources", false, "Keep KV namespaces without keys, D1 databases without tables and R2 buckets without data")
+	rootCmd.PersistentFlags().BoolVar(&config.EmptyR2BeforeDelete, "empty-r2-before-delete", false, "Delete the objects in R2 buckets so the buckets themselves can be deleted")
+	rootCmd.PersistentFlags().StringArrayVar(&config.Exclude, "exclude", nil, "Resource ID or name to never delete (repeatable)")
+
+	rootCmd.PersistentFlags().IntVar(&config.RetryMax, "retry-max", api.DefaultRetryMax, "Maximum retries for rate-limited API requests")
+	rootCmd.PersistentFlags().DurationVar(&config.RetryWaitMax, "retry-wait-max", api.DefaultRetryWaitMax, "Maximum wait before retrying a rate-limited request")
+
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", configfile.DefaultPath(), "Path to config file")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Config file profile to use")
+	rootCmd.PersistentFlags().DurationVar(&config.Timeout, "timeout", 0, "Abort if the run takes longer than this (e.g. 5m)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append structured JSON logs of API requests and deletions to this file")
+	rootCmd.PersistentFlags().StringVar(&config.JSONLogFile, "json-log", "", "Append a JSON line to this file for each analysis, resource deletion and result as it happens")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Usage helps with bad flags and arguments, not with failed runs
+		cmd.SilenceUsage = true
+
+		if noColor {
+			styles.DisableColors()
+		}
+
+		if err := loadConfigFile(cmd); err != nil {
+			return err
+		}
+
+		if err := openLogs(logFile, config.JSONLogFile); err != nil {
+			return err
+		}
+
+		if config.Timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), config.Timeout)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+		return nil
+	}
+
+	var (
+		since     string
+		sinceDays int
+	)
+	rootCmd.Flags().StringVar(&since, "since", "", "Skip workers modified after this date (RFC3339 or YYYY-MM-DD)")
+	rootCmd.Flags().IntVar(&sinceDays, "since-days", 0, "Skip workers modified in the last N days, like --since")
 
 	// Hidden flag for updating API key
 	var updateKey bool
 	rootCmd.Flags().BoolVar(&updateKey, "update-key", false, "Update stored API key")
 	rootCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		// Checked after the config file is applied, which can set either
+		if config.ExclusiveOnly && config.ForceDeleteShared {
+			return errors.New("--force-delete-shared and --exclusive-only can't be used together")
+		}
+
+		if since != "" && cmd.Flags().Changed("since-days") {
+			return errors.New("--since and --since-days can't be used together")
+		}
+		if since != "" {
+			t, err := parseSince(since)
+			if err != nil {
+				return err
+			}
+			config.Since = t
+		}
+		if cmd.Flags().Changed("since-days") {
+			if sinceDays < 1 {
+				return fmt.Errorf("invalid --since-days %d: use a number of days of 1 or more", sinceDays)
+			}
+			config.Since = time.Now().AddDate(0, 0, -sinceDays)
+		}
+
 		if updateKey {
-			authMgr := auth.NewManager()
+			authMgr := newAuthManager()
 			return authMgr.UpdateAPIKey()
 		}
 		return nil
 	}
 }
 
-func run(cmd *cobra.Command, args []string) error {
-	workerName := args[0]
+// loadConfigFile applies settings from the config file to any flag not set on the command line
+func loadConfigFile(cmd *cobra.Command) error {
+	file, err := configfile.Load(configPath, cmd.Flags().Changed("config"))
+	if err != nil {
+		return err
+	}
 
-	// Get API key
-	authMgr := auth.NewManager()
-	apiKey, err := authMgr.GetAPIKey()
+	settings, err := file.Resolve(profile)
 	if err != nil {
-		return fmt.Errorf("authentication failed: %w", err)
+		return err
+	}
+	activeProfile = profile
+	if activeProfile == "" {
+		activeProfile = file.DefaultProfile
+	}
+
+	// A token or account in the environment wins over the file, but not
+	// over the flags
+	if auth.EnvAPIKey() != "" {
+		settings.APIToken = nil
+	}
+	if accountID := os.Getenv(accountIDEnvVar); accountID != "" {
+		settings.AccountID = &accountID
+	}
+
+	settings.Apply(&config, cmd.Flags().Changed)
+	return nil
+}
+
+// openLogs sends structured logs to the file at logPath and event records
+// to the file at eventPath, appending to each. Either path may be empty
+func openLogs(logPath, eventPath string) error {
+	var (
+		handlers []slog.Handler
+		files    []*os.File
+	)
+	closeFiles := func() error {
+		var errs []error
+		for _, f := range files {
+			errs = append(errs, f.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	if logPath != "" {
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		files = append(files, f)
+		handlers = append(handlers, slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	if eventPath != "" {
+		f, err := os.OpenFile(eventPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			_ = closeFiles()
+			return fmt.Errorf("failed to open JSON log: %w", err)
+		}
+		files = append(files, f)
+		handlers = append(handlers, eventlog.NewHandler(f))
+	}
+
+	switch len(handlers) {
+	case 0:
+		return nil
+	case 1:
+		logger = slog.New(handlers[0])
+	default:
+		logger = slog.New(teeHandler(handlers))
+	}
+	closeLog = closeFiles
+	return nil
+}
+
+// teeHandler passes each record to every handler that is enabled for it
+type teeHandler []slog.Handler
+
+// Enabled implements slog.Handler
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+// WithGroup implements slog.Handler
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// isProtected reports whether a worker matches a --protect entry
+func isProtected(workerName string) bool {
+	for _, entry := range config.ProtectedWorkers {
+		if entry == workerName {
+			return true
+		}
+		if ok, _ := path.Match(entry, workerName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func errProtected(workerName string) error {
+	return exitcodes.WithCode(exitcodes.WorkerProtected, fmt.Errorf("worker %s is protected and cannot be deleted", workerName))
+}
+
+// checkRequiredTag refuses a worker missing the --require-tag tag
+func checkRequiredTag(worker *types.WorkerInfo) error {
+	if config.RequireTag == "" || slices.Contains(worker.Tags, config.RequireTag) {
+		return nil
+	}
+	return exitcodes.WithCode(exitcodes.WorkerProtected,
+		fmt.Errorf("worker %s is not tagged %s and will not be deleted", worker.Name, config.RequireTag))
+}
+
+// parseSince parses a --since value as RFC3339 or a plain YYYY-MM-DD date
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use RFC3339 or YYYY-MM-DD", value)
+}
+
+// planOptions returns the deletion plan options set on the command line
+func planOptions() analyzer.PlanOptions {
+	return analyzer.PlanOptions{
+		ExclusiveOnly: config.ExclusiveOnly,
+		SkipEmpty:     config.SkipEmptyResources,
+	}
+}
+
+// newDeleter creates a deleter for the configured run, applying --since
+func newDeleter(client *api.Client) *deleter.Deleter {
+	d := deleter.NewDeleter(client, config.DryRun)
+	d.SetSince(config.Since)
+	d.SetLogger(logger)
+	d.SetConcurrency(config.DeletionConcurrency)
+	d.AddValidator(deleter.NotInExcludeList(config.Exclude))
+	if config.ExclusiveOnly {
+		// Shared resources are never deleted, whatever the plan says
+		d.AddValidator(deleter.NotShared())
+	}
+	if config.ManifestFile != "" {
+		d.SetManifest(config.ManifestFile, config.AccountID)
+	}
+	if config.SnapshotDir != "" {
+		d.SetSnapshot(config.SnapshotDir, config.Force)
+	}
+	return d
+}
+
+// newAuthManager creates the credential manager for the active profile,
+// honouring --no-keychain
+func newAuthManager() *auth.Manager {
+	opts := []auth.Option{auth.WithProfile(activeProfile)}
+	if config.NoKeychain {
+		opts = append(opts, auth.WithoutKeychain())
 	}
+	return auth.NewManager(opts...)
+}
+
+// resolveAPIKey returns the API token to use: --api-token, then
+// CLOUDFLARE_API_TOKEN, CF_API_TOKEN, the config file, stored credentials and
+// a prompt. The config file's token is only in config.APIKey when neither the
+// flag nor the environment set one.
+func resolveAPIKey() (string, error) {
+	if config.APIKey != "" {
+		return config.APIKey, nil
+	}
+	return newAuthManager().GetAPIKey()
+}
 
+// newClient authenticates and creates an API client for the configured account
+func newClient(ctx context.Context) (*api.Client, error) {
+	apiKey, err := resolveAPIKey()
+	if err != nil {
+		return nil, exitcodes.WithCode(exitcodes.AuthFailure, fmt.Errorf("authentication failed: %w", err))
+	}
 	config.APIKey = apiKey
 
 	// Create API client
-	client, err := api.NewClient(apiKey, config.AccountID)
+	client, err := api.NewClient(apiKey, config.AccountID,
+		api.WithContext(ctx),
+		api.WithRetry(config.RetryMax, config.RetryWaitMax),
+		api.WithLogger(logger))
 	if err != nil {
-		return fmt.Errorf("failed to create API client: %w", err)
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	// Catch expired or revoked tokens here rather than as a 403 halfway through
+	if err := client.ValidateAPIToken(); err != nil {
+		var authErr *apperrors.AuthError
+		if errors.As(err, &authErr) {
+			return nil, tokenError(authErr)
+		}
+		return nil, err
 	}
 
 	// Get account ID if not provided
 	if config.AccountID == "" {
 		accountID, err := client.GetAccountID()
+		if errors.Is(err, api.ErrMultipleAccounts) && canPrompt() {
+			accountID, err = selectAccount(client)
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 		config.AccountID = accountID
 	}
 
+	return client, nil
+}
+
+// tokenError explains a rejected token with where to create a new one and the
+// permissions it needs
+func tokenError(err *apperrors.AuthError) error {
+	var b strings.Builder
+	b.WriteString(err.Message)
+	b.WriteString("\n\nCreate a new token at: " + auth.TokenURL)
+	b.WriteString("\nRequired permissions:")
+	for _, permission := range auth.RequiredPermissions {
+		b.WriteString("\n  • " + permission)
+	}
+	return &apperrors.AuthError{Message: b.String()}
+}
+
+// canPrompt reports whether the run may ask the user questions
+func canPrompt() bool {
+	return !config.Force && !config.AutoYes && !config.JSONOutput && !config.Summary &&
+		term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// selectAccount lets the user pick one of the token's accounts in the TUI
+func selectAccount(client *api.Client) (string, error) {
+	finalModel, err := tea.NewProgram(models.NewAccountSelectModel(client.ListAccounts)).Run()
+	if err != nil {
+		return "", fmt.Errorf("UI error: %w", err)
+	}
+
+	m := finalModel.(models.Model)
+	if m.Err != nil {
+		return "", m.Err
+	}
+	if m.SelectedAccountID == "" {
+		return "", exitcodes.WithCode(exitcodes.UserCancelled, errors.New("no account selected"))
+	}
+
+	client.SetAccountID(m.SelectedAccountID)
+	return m.SelectedAccountID, nil
+}
+
+// newAnalyzer creates an analyzer configured from the command line
+func newAnalyzer(client *api.Client, extra ...analyzer.Option) (*analyzer.Analyzer, error) {
+	// Without the key, table and storage counts every resource would look empty
+	if config.SkipEmptyResources && config.NoEnrichment {
+		return nil, errors.New("--skip-empty-resources can't be combined with --no-enrichment")
+	}
+
+	opts := append([]analyzer.Option{
+		analyzer.WithConcurrency(config.AnalysisConcurrency),
+		analyzer.WithLogger(logger),
+	}, extra...)
+	if config.NoEnrichment {
+		opts = append(opts, analyzer.WithoutEnrichment())
+	}
+	if config.TagFilter != "" {
+		opts = append(opts, analyzer.WithTagFilter(config.TagFilter))
+	}
+	if config.RegionFilter != "" {
+		opts = append(opts, analyzer.WithRegionFilter(config.RegionFilter))
+	}
+	if config.MaxWorkers > 0 {
+		opts = append(opts, analyzer.WithMaxWorkers(config.MaxWorkers))
+	}
+	if len(config.Exclude) > 0 {
+		opts = append(opts, analyzer.WithExclude(config.Exclude))
+	}
+	if config.SkipWorkerDeletion {
+		opts = append(opts, analyzer.WithSkipWorkerDeletion())
+	}
+	if config.DeleteRoutes {
+		opts = append(opts, analyzer.WithDeleteRoutes())
+	}
+	if config.DeleteSecrets {
+		opts = append(opts, analyzer.WithDeleteSecrets())
+	}
+	if config.EmptyR2BeforeDelete {
+		opts = append(opts, analyzer.WithEmptyR2Buckets())
+	}
+	if len(config.Include) > 0 {
+		include := make([]types.BindingType, 0, len(config.Include))
+		for _, name := range config.Include {
+			t, err := types.ParseBindingType(name)
+			if err != nil {
+				return nil, err
+			}
+			include = append(include, t)
+		}
+		opts = append(opts, analyzer.WithInclude(include))
+	}
+	if len(skipTypes) > 0 {
+		config.SkipResourceTypes = make([]types.BindingType, 0, len(skipTypes))
+		for _, name := range skipTypes {
+			t, err := types.ParseBindingType(name)
+			if err != nil {
+				return nil, err
+			}
+			config.SkipResourceTypes = append(config.SkipResourceTypes, t)
+		}
+		opts = append(opts, analyzer.WithSkipTypes(config.SkipResourceTypes))
+	}
+	return analyzer.NewAnalyzer(client, opts...), nil
+}
+
+// getWorker fetches a worker, tagging a missing worker with its exit code
+func getWorker(client *api.Client, workerName string) (*types.WorkerInfo, error) {
+	worker, err := client.GetWorker(workerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worker: %w", err)
+	}
+	return worker, nil
+}
+
+// attachUsageMetrics records the worker's recent traffic, shown when it was
+// last active. The metrics are informational, a failed lookup leaves them nil.
+func attachUsageMetrics(client *api.Client, worker *types.WorkerInfo) {
+	if metrics, err := client.GetWorkerUsageMetrics(worker.Name, usageMetricsWindow); err == nil {
+		worker.Metrics = metrics
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	var err error
+	switch {
+	case purgeAll:
+		if err := checkPurgeAllFlags(cmd); err != nil {
+			return err
+		}
+		err = purgeAccount(cmd.Context())
+	case assertExclusive:
+		if err := checkAssertExclusiveFlags(); err != nil {
+			return err
+		}
+		err = checkExclusive(cmd.Context(), args[0])
+	case diffFile != "":
+		err = showPlanDiff(cmd.Context(), args[0])
+	case config.WorkersFile != "":
+		err = purgeBatch(cmd.Context(), config.WorkersFile)
+	case config.NameRegex != "":
+		err = planMatchingRegex(cmd.Context(), config.NameRegex)
+	case isWorkerPattern(args[0]):
+		err = purgeMatching(cmd.Context(), args[0])
+	default:
+		// Refuse before any API call is made
+		if isProtected(args[0]) {
+			err = errProtected(args[0])
+			break
+		}
+		err = purge(cmd.Context(), args[0])
+	}
+
+	// In JSON mode errors are reported in the JSON document, not on stderr
+	if err != nil && config.JSONOutput && !config.Summary && !isReported(err) {
+		_ = outputJSON(nil, nil, err)
+		return reported(err)
+	}
+
+	return err
+}
+
+func purge(ctx context.Context, workerName string) error {
+	// Summary and JSON modes suppress all intermediate human-readable output
+	if config.Summary || config.JSONOutput {
+		config.Quiet = true
+	}
+
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Show progress
 	if !config.Quiet {
 		fmt.Println(views.RenderHeader())
@@ -86,22 +619,41 @@ func run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get worker details
-	worker, err := client.GetWorker(workerName)
+	worker, err := getWorker(client, workerName)
 	if err != nil {
-		return fmt.Errorf("failed to get worker: %w", err)
+		return err
 	}
+	if err := checkRequiredTag(worker); err != nil {
+		return err
+	}
+	attachUsageMetrics(client, worker)
 
 	if !config.Quiet {
 		fmt.Println(views.RenderSuccess("Worker found"))
 	}
 
+	// In verbose mode, show worker details with a sample of recent errors
+	if config.Verbose && !config.Quiet {
+		events, err := client.GetWorkerTailEventSample(workerName, recentErrorWindow)
+		if err != nil {
+			fmt.Println(views.RenderWarning(fmt.Sprintf("Could not fetch recent errors: %v", err)))
+		} else {
+			worker.RecentErrors = events
+		}
+		fmt.Println(views.RenderWorkerInfo(worker))
+	}
+
 	// Create analyzer and deleter
-	a := analyzer.NewAnalyzer(client)
-	d := deleter.NewDeleter(client, config.DryRun)
+	// The target worker's bindings are already fetched, don't request them again
+	a, err := newAnalyzer(client, analyzer.WithWorkerCache(worker))
+	if err != nil {
+		return err
+	}
+	d := newDeleter(client)
 
 	// Interactive mode - run analysis inside TUI
 	if !config.Force && !config.AutoYes && !config.DryRun && !config.JSONOutput {
-		p := tea.NewProgram(models.NewModelWithAnalysis(worker, a, &config, d))
+		p := tea.NewProgram(models.NewModelWithAnalysis(worker, a, &config, d).WithContext(ctx))
 		finalModel, err := p.Run()
 		if err != nil {
 			return fmt.Errorf("UI error: %w", err)
@@ -109,15 +661,29 @@ func run(cmd *cobra.Command, args []string) error {
 
 		// Check the final state
 		m := finalModel.(models.Model)
+		if m.Plan() != nil {
+			writeAudit(workerName, m.Plan(), nil)
+		}
+		if m.Result != nil {
+			writeAudit(workerName, nil, m.Result)
+			writeReport(m.Plan(), m.Result)
+			sendWebhook(ctx, m.Result)
+			eventlog.Result(logger, workerName, m.Result)
+		}
+		lastResult = m.Result
+
+		if config.Summary {
+			outputSummary(workerName, m.Result, m.Err)
+		}
 
 		// If there's an error, return it
 		if m.Err != nil {
 			return fmt.Errorf("deletion failed: %w", m.Err)
 		}
 
-		// If result exists but not successful, exit with error
-		if m.Result != nil && !m.Result.Success {
-			os.Exit(1)
+		// Cancelled or partially failed runs exit with their own code
+		if code := m.ExitCode(); code != exitcodes.Success {
+			return exitWith(code)
 		}
 
 		return nil
@@ -134,7 +700,12 @@ func run(cmd *cobra.Command, args []string) error {
 		fmt.Println("This checks if other workers share the same resources to prevent accidental deletion.")
 		fmt.Println("If you're certain no other workers use these resources, you can skip this step.")
 		fmt.Println()
-		fmt.Print("Run dependency analysis? [Y/n]: ")
+		if count, err := a.CountWorkers(); err == nil {
+			fmt.Print(views.RenderAnalysisEstimate(count, a.EstimateAnalysisDuration(count)))
+			fmt.Print(" Run full analysis? [Y/n]: ")
+		} else {
+			fmt.Print("Run dependency analysis? [Y/n]: ")
+		}
 
 		var response string
 		fmt.Scanln(&response)
@@ -193,15 +764,22 @@ func run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create deletion plan
-	plan := a.CreateDeletionPlan(worker, resources, config.ExclusiveOnly)
+	plan := a.CreateDeletionPlan(worker, resources, planOptions())
+	writeAudit(workerName, plan, nil)
 
-	// If JSON output, print and exit
-	if config.JSONOutput {
-		return outputJSON(plan)
+	// In JSON mode, only delete when prompts were explicitly skipped; otherwise print the plan and exit
+	if config.JSONOutput && (config.DryRun || (!config.Force && !config.AutoYes)) {
+		if config.Summary {
+			return printSummary(summary{Worker: workerName, Status: "planned", Resources: plan.Summary().TotalResources})
+		}
+		return outputJSON(plan, nil, nil)
 	}
 
 	// In dry-run mode, just show the plan
 	if config.DryRun {
+		if config.Summary {
+			return printSummary(summary{Worker: workerName, Status: "planned", Resources: plan.Summary().TotalResources})
+		}
 		fmt.Println(views.RenderDeletionPlan(plan))
 		fmt.Println(views.RenderWarning("DRY RUN - No changes were made"))
 		return nil
@@ -215,12 +793,40 @@ func run(cmd *cobra.Command, args []string) error {
 	// Set deletion flags based on config
 	if config.ExclusiveOnly {
 		plan.DeleteShared = false
-	} else if config.Force || config.AutoYes {
+	} else if config.ForceDeleteShared || config.Force || config.AutoYes {
 		plan.DeleteShared = true
 	}
 
-	result, err := d.Execute(plan)
+	result, err := d.ExecuteWithRollback(plan, deletionProgress(plan))
+	if err == nil && config.WaitForPropagation {
+		awaitPropagation(d, result, workerName)
+	}
+	if result != nil {
+		writeAudit(workerName, nil, result)
+		writeReport(plan, result)
+		sendWebhook(ctx, result)
+		eventlog.Result(logger, workerName, result)
+	}
+	lastResult = result
+	if config.Summary {
+		outputSummary(workerName, result, err)
+	} else if config.JSONOutput {
+		if encErr := outputJSON(plan, result, err); encErr != nil {
+			return encErr
+		}
+		if err != nil {
+			return reported(err)
+		}
+		if code := exitcodes.ForResult(result, nil); code != exitcodes.Success {
+			return exitWith(code)
+		}
+		return nil
+	}
 	if err != nil {
+		// Show what was already deleted before the failure
+		if result != nil && result.PartialFailure && !config.Quiet {
+			fmt.Println(views.RenderDeletionResult(result))
+		}
 		return fmt.Errorf("deletion failed: %w", err)
 	}
 
@@ -229,20 +835,326 @@ func run(cmd *cobra.Command, args []string) error {
 		fmt.Println(views.RenderDeletionResult(result))
 	}
 
-	if !result.Success {
-		os.Exit(1)
+	if code := exitcodes.ForResult(result, nil); code != exitcodes.Success {
+		return exitWith(code)
 	}
 
 	return nil
 }
 
-func outputJSON(plan *types.DeletionPlan) error {
-	// TODO: Implement JSON output
-	fmt.Println("JSON output not yet implemented")
+// awaitPropagation waits for the deleted worker to stop being served, with a
+// spinner on interactive terminals
+func awaitPropagation(d *deleter.Deleter, result *types.DeletionResult, workerName string) {
+	if config.Quiet || !term.IsTerminal(int(os.Stdout.Fd())) {
+		d.AwaitPropagation(result, workerName, config.PropagationTimeout)
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.AwaitPropagation(result, workerName, config.PropagationTimeout)
+		close(done)
+	}()
+
+	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			fmt.Print("\r\033[K")
+			return
+		case <-ticker.C:
+			fmt.Printf("\r\033[K%s Waiting for the deletion to propagate", styles.Info.Render(frames[i%len(frames)]))
+		}
+	}
+}
+
+// deletionProgress prints a line as each resource in the plan starts to be
+// deleted, or returns nil when the output must stay machine readable
+func deletionProgress(plan *types.DeletionPlan) deleter.ProgressCallback {
+	if config.Quiet || config.JSONOutput || config.Summary {
+		return nil
+	}
+
+	resourceTypes := make(map[string]types.BindingType, len(plan.ResourcesToDelete))
+	for _, resource := range plan.ResourcesToDelete {
+		resourceTypes[resource.ResourceName] = resource.ResourceType
+	}
+
+	// The callback fires before and after each resource, only announce the
+	// start. With --concurrency the calls interleave, so track what started.
+	started := make(map[string]bool)
+	return func(current, total int, resourceName string) {
+		if started[resourceName] {
+			return
+		}
+		started[resourceName] = true
+		resourceType := styles.FormatResourceType(string(resourceTypes[resourceName]))
+		fmt.Println(views.RenderProgress(fmt.Sprintf("Deleting %s %s (%d/%d)", resourceType, resourceName, len(started), total)))
+	}
+}
+
+// writeAudit appends a plan or result record to the --output-file audit trail.
+// Failing to write the record is reported but doesn't stop the run.
+func writeAudit(workerName string, plan *types.DeletionPlan, result *types.DeletionResult) {
+	if config.OutputFile == "" {
+		return
+	}
+
+	err := audit.Append(config.OutputFile, audit.Record{
+		AccountID: config.AccountID,
+		Worker:    workerName,
+		DryRun:    config.DryRun,
+		Plan:      plan,
+		Result:    result,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, views.RenderWarning(err.Error()))
+	}
+}
+
+// writeReport writes the --report HTML page for a finished deletion. Failing
+// to write it is reported but doesn't change the outcome of the run.
+func writeReport(plan *types.DeletionPlan, result *types.DeletionResult) {
+	if config.ReportFile == "" {
+		return
+	}
+
+	if err := os.WriteFile(config.ReportFile, []byte(report.GenerateHTML(result, plan)), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, views.RenderWarning(fmt.Sprintf("failed to write report: %v", err)))
+	}
+}
+
+// sendWebhook posts a finished deletion to --webhook, even when the run
+// timed out. A failed call is reported but doesn't change the outcome of the
+// run.
+func sendWebhook(ctx context.Context, result *types.DeletionResult) {
+	if config.Webhook == "" {
+		return
+	}
+
+	secret := config.WebhookSecret
+	if secret == "" {
+		secret = os.Getenv(webhook.SecretEnvVar)
+	}
+	if err := webhook.Send(context.WithoutCancel(ctx), config.Webhook, secret, result); err != nil {
+		fmt.Fprintln(os.Stderr, views.RenderWarning(err.Error()))
+	}
+}
+
+// jsonOutput is the document written to stdout in --json mode
+type jsonOutput struct {
+	Plan   *types.DeletionPlan   `json:"plan,omitempty"`
+	Result *types.DeletionResult `json:"result,omitempty"`
+	Errors []string              `json:"errors"`
+}
+
+// outputJSON writes the plan, the result (if deletion ran) and all errors as JSON
+func outputJSON(plan *types.DeletionPlan, result *types.DeletionResult, runErr error) error {
+	out := jsonOutput{
+		Plan:   plan,
+		Result: result,
+		Errors: []string{},
+	}
+
+	if result != nil {
+		for _, err := range result.Errors {
+			out.Errors = append(out.Errors, err.Error())
+		}
+	}
+	if runErr != nil && (result == nil || len(result.Errors) == 0) {
+		out.Errors = append(out.Errors, runErr.Error())
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// summary is the single-line outcome printed in --summary mode
+type summary struct {
+	Worker    string `json:"worker"`
+	Status    string `json:"status"`
+	Resources int    `json:"resources"`
+	Errors    int    `json:"errors"`
+}
+
+// outputSummary derives the outcome of a run and prints it as a summary line
+func outputSummary(workerName string, result *types.DeletionResult, err error) {
+	s := summary{Worker: workerName}
+
+	switch {
+	case result != nil:
+		s.Resources = len(result.ResourcesDeleted)
+		s.Errors = len(result.Errors)
+		switch {
+		case result.SkippedReason != "":
+			s.Status = string(types.OutcomeSkipped)
+		case result.Success:
+			s.Status = "deleted"
+		default:
+			s.Status = "failed"
+		}
+	case err != nil:
+		s.Status = "failed"
+		s.Errors = 1
+	default:
+		s.Status = "aborted"
+	}
+
+	_ = printSummary(s)
+}
+
+// printSummary prints the summary as plain text or JSON
+func printSummary(s summary) error {
+	if config.JSONOutput {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to encode summary: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	switch s.Status {
+	case "aborted":
+		fmt.Printf("%s: %s\n", s.Worker, s.Status)
+	default:
+		fmt.Printf("%s: %s (%d resources, %d errors)\n", s.Worker, s.Status, s.Resources, s.Errors)
+	}
 	return nil
 }
 
-// Execute runs the root command
-func Execute() error {
-	return rootCmd.Execute()
+// Execute runs the root command and exits with the code matching the outcome
+func Execute() {
+	// Honour https://no-color.org and dumb terminals before anything renders
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		styles.DisableColors()
+	}
+
+	_, err := ExecuteWithResult(os.Args[1:])
+	if err != nil {
+		if !isReported(err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(exitcodes.FromError(err))
+	}
+	notifyUpdate(lastCommand)
+}
+
+// ExecuteWithResult runs the command line in args, as Execute does without
+// exiting the process, and returns the result of deleting a single worker
+// alongside any error. The result is nil when nothing was deleted, including
+// batch runs. The error carries the exit code Execute would exit with, read
+// it with exitcodes.FromError. Flags are reset to their defaults before each
+// run.
+func ExecuteWithResult(args []string) (*types.DeletionResult, error) {
+	config = types.Config{}
+	lastResult = nil
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(args)
+
+	cmd, err := rootCmd.ExecuteC()
+	lastCommand = cmd
+	if cancelTimeout != nil {
+		cancelTimeout()
+		cancelTimeout = nil
+	}
+	if err != nil {
+		logger.Error("run failed", "error", err.Error())
+	}
+	if closeLog != nil {
+		_ = closeLog()
+		closeLog = nil
+		logger = slog.New(slog.DiscardHandler)
+	}
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s: %w", config.Timeout, err)
+	}
+
+	var coded *exitcodes.Error
+	if err != nil && !errors.As(err, &coded) {
+		err = exitcodes.WithCode(exitCode(err), err)
+	}
+	return lastResult, err
+}
+
+// resetFlags sets every flag of cmd and its subcommands back to its default,
+// so a run doesn't inherit the flags of the one before it
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// reportedError marks an error whose outcome was already written to stdout,
+// as a JSON document or a result, so Execute exits with its code silently
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// reported marks err as already reported
+func reported(err error) error {
+	return &reportedError{err: err}
+}
+
+// exitWith ends a run that already reported its outcome with code
+func exitWith(code int) error {
+	return reported(exitcodes.WithCode(code, fmt.Errorf("exit status %d", code)))
+}
+
+func isReported(err error) bool {
+	var r *reportedError
+	return errors.As(err, &r)
+}
+
+// exitCode maps err to the process exit code. A code attached with
+// exitcodes.WithCode wins, otherwise the error's type picks it.
+func exitCode(err error) int {
+	var coded *exitcodes.Error
+	if err == nil || errors.As(err, &coded) {
+		return exitcodes.FromError(err)
+	}
+
+	var (
+		notFound  *apperrors.WorkerNotFoundError
+		authErr   *apperrors.AuthError
+		partial   *apperrors.PartialDeletionError
+		rateLimit *apperrors.RateLimitError
+	)
+	switch {
+	case errors.As(err, &notFound):
+		return exitcodes.WorkerNotFound
+	case errors.As(err, &authErr):
+		return exitcodes.AuthFailure
+	case errors.As(err, &partial):
+		return exitcodes.PartialDeletion
+	case errors.As(err, &rateLimit):
+		return exitcodes.RateLimited
+	default:
+		return exitcodes.GeneralError
+	}
 }
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func TestCredentialPrecedence(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		wantToken   string
+		wantAccount string
+	}{
+		{
+			name: "flags override the environment",
+			args: []string{"--api-token", "flag-token", "--account-id", "flag-account"},
+			env: map[string]string{
+				"CLOUDFLARE_API_TOKEN":  "cloudflare-token",
+				"CF_API_TOKEN":          "cf-token",
+				"CLOUDFLARE_ACCOUNT_ID": "env-account",
+			},
+			wantToken:   "flag-token",
+			wantAccount: "flag-account",
+		},
+		{
+			name: "CLOUDFLARE_API_TOKEN before CF_API_TOKEN",
+			env: map[string]string{
+				"CLOUDFLARE_API_TOKEN":  "cloudflare-token",
+				"CF_API_TOKEN":          "cf-token",
+				"CLOUDFLARE_ACCOUNT_ID": "env-account",
+			},
+			wantToken:   "cloudflare-token",
+			wantAccount: "env-account",
+		},
+		{
+			name:        "CF_API_TOKEN before the config file",
+			env:         map[string]string{"CF_API_TOKEN": "cf-token"},
+			wantToken:   "cf-token",
+			wantAccount: "file-account",
+		},
+		{
+			name:        "config file without flags or environment",
+			wantToken:   "file-token",
+			wantAccount: "file-account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"CLOUDFLARE_API_TOKEN", "CF_API_TOKEN", accountIDEnvVar} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			savedConfig, savedPath := config, configPath
+			t.Cleanup(func() { config, configPath = savedConfig, savedPath })
+			config = types.Config{NoKeychain: true}
+
+			configPath = filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(configPath, []byte("api_token = \"file-token\"\naccount_id = \"file-account\"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := &cobra.Command{}
+			cmd.Flags().StringVar(&config.APIKey, "api-token", "", "")
+			cmd.Flags().StringVar(&config.AccountID, "account-id", "", "")
+			cmd.Flags().StringVar(&configPath, "config", configPath, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := loadConfigFile(cmd); err != nil {
+				t.Fatalf("loadConfigFile() error = %v", err)
+			}
+			token, err := resolveAPIKey()
+			if err != nil {
+				t.Fatalf("resolveAPIKey() error = %v", err)
+			}
+			if token != tt.wantToken {
+				t.Errorf("token = %q, want %q", token, tt.wantToken)
+			}
+			if config.AccountID != tt.wantAccount {
+				t.Errorf("account = %q, want %q", config.AccountID, tt.wantAccount)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/manifest"
+	"github.com/mattietk/cf-purge-worker/internal/ui/styles"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo <manifest-file>",
+	Short: "Re-create the resources recorded by --save-manifest as empty shells",
+	Long: `Re-create the KV namespaces, R2 buckets and D1 databases recorded in a
+manifest written by --save-manifest. Their data is gone, so they come back
+empty: KV namespaces with the same title, R2 buckets with the same name (and
+so the same ID) and D1 databases with the same name. KV and D1 get new IDs,
+update any bindings that refer to them. Worker scripts and other resource
+types can't be restored.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().BoolVarP(&config.DryRun, "dry-run", "d", false, "Show what would be re-created without making changes")
+	rootCmd.AddCommand(undoCmd)
+}
+
+// undoOutcome is what happened to one manifest entry
+type undoOutcome struct {
+	Worker string            `json:"worker"`
+	Type   types.BindingType `json:"type"`
+	Name   string            `json:"name"`
+	OldID  string            `json:"old_id"`
+	NewID  string            `json:"new_id,omitempty"`
+	Status string            `json:"status"` // recreated, planned, unsupported or failed
+	Error  string            `json:"error,omitempty"`
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	m, err := manifest.Read(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Resources must come back in the account they were deleted from
+	if config.AccountID == "" {
+		config.AccountID = m.AccountID
+	} else if m.AccountID != "" && config.AccountID != m.AccountID {
+		return fmt.Errorf("manifest is for account %s, not %s", m.AccountID, config.AccountID)
+	}
+
+	client, err := newClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var outcomes []undoOutcome
+	failed := false
+	for _, entry := range m.Workers {
+		if !config.JSONOutput {
+			fmt.Println(views.RenderProgress(fmt.Sprintf("Restoring resources of %s (the worker script itself can't be restored)", entry.Worker)))
+		}
+		for _, resource := range entry.Resources {
+			outcome := recreateResource(client, entry.Worker, resource)
+			if outcome.Status == "failed" {
+				failed = true
+			}
+			outcomes = append(outcomes, outcome)
+			if !config.JSONOutput {
+				fmt.Println(renderUndoOutcome(outcome))
+			}
+		}
+	}
+
+	if config.JSONOutput {
+		data, err := json.MarshalIndent(outcomes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+	}
+
+	if failed {
+		return exitcodes.WithCode(exitcodes.GeneralError, fmt.Errorf("some resources could not be re-created"))
+	}
+	return nil
+}
+
+// recreateResource re-creates one deleted resource as an empty shell
+func recreateResource(client *api.Client, worker string, resource manifest.Resource) undoOutcome {
+	outcome := undoOutcome{
+		Worker: worker,
+		Type:   resource.Type,
+		Name:   resource.Name,
+		OldID:  resource.ID,
+	}
+
+	switch resource.Type {
+	case types.BindingTypeKV, types.BindingTypeR2, types.BindingTypeD1:
+	default:
+		outcome.Status = "unsupported"
+		return outcome
+	}
+
+	if config.DryRun {
+		outcome.Status = "planned"
+		return outcome
+	}
+
+	var err error
+	switch resource.Type {
+	case types.BindingTypeKV:
+		outcome.NewID, err = client.CreateKVNamespace(resource.Name)
+	case types.BindingTypeR2:
+		// Bucket names are their IDs, so the binding keeps working
+		err = client.CreateR2Bucket(resource.ID, strings.ToLower(resource.Location))
+		outcome.NewID = resource.ID
+	case types.BindingTypeD1:
+		outcome.NewID, err = client.CreateD1Database(resource.Name)
+	}
+
+	if err != nil {
+		outcome.Status = "failed"
+		outcome.Error = err.Error()
+		return outcome
+	}
+	outcome.Status = "recreated"
+	return outcome
+}
+
+func renderUndoOutcome(o undoOutcome) string {
+	label := fmt.Sprintf("%s %s", styles.FormatResourceType(string(o.Type)), o.Name)
+	switch o.Status {
+	case "recreated":
+		if o.NewID != o.OldID {
+			return views.RenderSuccess(fmt.Sprintf("Re-created %s (new ID %s, was %s)", label, o.NewID, o.OldID))
+		}
+		return views.RenderSuccess(fmt.Sprintf("Re-created %s", label))
+	case "planned":
+		return views.RenderMuted(fmt.Sprintf("Would re-create %s", label))
+	case "unsupported":
+		return views.RenderMuted(fmt.Sprintf("⊗ %s can't be re-created", label))
+	default:
+		return views.RenderError(fmt.Sprintf("Failed to re-create %s: %s", label, o.Error))
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/internal/ui/styles"
+	"github.com/mattietk/cf-purge-worker/internal/update"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// updateCheckTimeout bounds the automatic check so it never holds up a run
+const updateCheckTimeout = 2 * time.Second
+
+var versionCheck bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the version, optionally checking GitHub for a newer release",
+	Args:  cobra.NoArgs,
+	RunE:  runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub for a newer release")
+	rootCmd.PersistentFlags().BoolVar(&config.NoUpdateCheck, "no-update-check", false, "Don't check GitHub for a newer release after a run")
+	rootCmd.AddCommand(versionCmd)
+}
+
+// versionOutput is the JSON shape of the version command
+type versionOutput struct {
+	Version         string `json:"version"`
+	Latest          string `json:"latest,omitempty"`
+	UpdateAvailable bool   `json:"update_available"`
+	URL             string `json:"url,omitempty"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	out := versionOutput{Version: rootCmd.Version}
+
+	var result *update.Result
+	if versionCheck {
+		var err error
+		result, err = update.Check(cmd.Context(), update.DefaultCachePath())
+		if err != nil {
+			return err
+		}
+		out.Latest = result.Latest
+		out.UpdateAvailable = result.NewerThan(rootCmd.Version)
+		out.URL = result.URL
+	}
+
+	if config.JSONOutput {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("cf-purge-worker %s\n", out.Version)
+	switch {
+	case result == nil:
+	case out.UpdateAvailable:
+		printUpdateNotice(os.Stdout, result)
+	default:
+		fmt.Println(styles.Success.Render("You are running the latest release"))
+	}
+	return nil
+}
+
+// notifyUpdate prints an upgrade notice to stderr after a run when a newer
+// release is out. It stays quiet for machine-readable output and when stderr
+// isn't a terminal, and ignores any failure to reach GitHub.
+func notifyUpdate(cmd *cobra.Command) {
+	if config.NoUpdateCheck || config.JSONOutput || config.Quiet || config.Summary || cmd == versionCmd {
+		return
+	}
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	result, err := update.Check(ctx, update.DefaultCachePath())
+	if err != nil || !result.NewerThan(rootCmd.Version) {
+		return
+	}
+	printUpdateNotice(os.Stderr, result)
+}
+
+func printUpdateNotice(w *os.File, result *update.Result) {
+	fmt.Fprintln(w, styles.Warning.Render(
+		fmt.Sprintf("A new release of cf-purge-worker is available: %s → %s", rootCmd.Version, result.Latest)))
+	if result.URL != "" {
+		fmt.Fprintln(w, styles.Muted.Render(result.URL))
+	}
+}
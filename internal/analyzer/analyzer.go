@@ -2,33 +2,238 @@ package analyzer
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/eventlog"
 	"github.com/mattietk/cf-purge-worker/pkg/types"
 )
 
+// estimatedLatencyPerWorker is the typical time to fetch one worker's bindings
+const estimatedLatencyPerWorker = 400 * time.Millisecond
+
+// defaultConcurrency is the number of workers fetched in parallel during analysis
+const defaultConcurrency = 10
+
 // ProgressCallback is called during analysis to report progress
 type ProgressCallback func(current, total int, workerName string)
 
 // Analyzer analyzes worker dependencies
 type Analyzer struct {
-	client *api.Client
+	client        api.ClientInterface
+	concurrency   int
+	noEnrichment  bool
+	exclude       []string
+	include       []types.BindingType
+	skipTypes     []types.BindingType
+	keepWorker    bool
+	deleteRoutes  bool
+	deleteSecrets bool
+	emptyR2       bool
+	maxWorkers    int
+	tagFilter     string
+	regionFilter  string
+	logger        *slog.Logger
+
+	// skippedWorkers are the workers the last dependency analysis couldn't read
+	skippedWorkers []string
+	// truncated is set when the last dependency analysis hit maxWorkers
+	truncated bool
+
+	// bindingCache holds worker bindings keyed by worker name, nameCache
+	// holds resolved resource names keyed by resource key
+	bindingCache *ttlCache[[]types.Binding]
+	nameCache    *ttlCache[string]
+}
+
+// Option configures an Analyzer
+type Option func(*Analyzer)
+
+// WithConcurrency sets how many workers are fetched in parallel during analysis
+func WithConcurrency(n int) Option {
+	return func(a *Analyzer) {
+		if n > 0 {
+			a.concurrency = n
+		}
+	}
+}
+
+// WithoutEnrichment skips the extra API calls that resolve resource names,
+// showing resource IDs instead
+func WithoutEnrichment() Option {
+	return func(a *Analyzer) {
+		a.noEnrichment = true
+	}
+}
+
+// WithExclude keeps resources whose ID or name matches an entry out of
+// deletion plans
+func WithExclude(exclude []string) Option {
+	return func(a *Analyzer) {
+		a.exclude = exclude
+	}
+}
+
+// WithInclude limits deletion plans to resources of the given types
+func WithInclude(include []types.BindingType) Option {
+	return func(a *Analyzer) {
+		a.include = include
+	}
+}
+
+// WithSkipTypes keeps resources of the given types out of deletion plans,
+// listing them as skipped
+func WithSkipTypes(skip []types.BindingType) Option {
+	return func(a *Analyzer) {
+		a.skipTypes = skip
+	}
+}
+
+// WithCacheTTL sets how long fetched bindings and resource names are reused.
+// A TTL of zero disables caching.
+func WithCacheTTL(d time.Duration) Option {
+	return func(a *Analyzer) {
+		a.bindingCache.setTTL(d)
+		a.nameCache.setTTL(d)
+	}
+}
+
+// WithSkipWorkerDeletion creates plans that keep the worker script and
+// delete only its resources
+func WithSkipWorkerDeletion() Option {
+	return func(a *Analyzer) {
+		a.keepWorker = true
+	}
+}
+
+// WithDeleteSecrets creates plans that delete the secrets of a worker kept by
+// WithSkipWorkerDeletion. A deleted worker takes its secrets with it.
+func WithDeleteSecrets() Option {
+	return func(a *Analyzer) {
+		a.deleteSecrets = true
+	}
+}
+
+// WithDeleteRoutes creates plans that delete the worker's zone routes
+// before the worker itself
+func WithDeleteRoutes() Option {
+	return func(a *Analyzer) {
+		a.deleteRoutes = true
+	}
+}
+
+// WithEmptyR2Buckets creates plans that delete the objects in R2 buckets
+// before the buckets themselves
+func WithEmptyR2Buckets() Option {
+	return func(a *Analyzer) {
+		a.emptyR2 = true
+	}
+}
+
+// WithLogger logs the start and end of each analysis
+func WithLogger(logger *slog.Logger) Option {
+	return func(a *Analyzer) {
+		a.logger = logger
+	}
+}
+
+// WithMaxWorkers caps how many workers dependency analysis scans, most
+// recently modified first. Zero scans every worker.
+func WithMaxWorkers(n int) Option {
+	return func(a *Analyzer) {
+		if n > 0 {
+			a.maxWorkers = n
+		}
+	}
+}
+
+// WithTagFilter limits deletion plans to resources carrying tag
+func WithTagFilter(tag string) Option {
+	return func(a *Analyzer) {
+		a.tagFilter = tag
+	}
+}
+
+// WithRegionFilter keeps R2 buckets outside the jurisdiction region (eu,
+// fedramp, or default for buckets without one) out of deletion plans. Other
+// resource types are unaffected.
+func WithRegionFilter(region string) Option {
+	return func(a *Analyzer) {
+		a.regionFilter = region
+	}
+}
+
+// WithWorkerCache seeds the binding cache with an already fetched worker so
+// analysis doesn't request its bindings again
+func WithWorkerCache(w *types.WorkerInfo) Option {
+	return func(a *Analyzer) {
+		if w != nil {
+			a.bindingCache.set(w.Name, w.Bindings)
+		}
+	}
 }
 
 // NewAnalyzer creates a new analyzer
-func NewAnalyzer(client *api.Client) *Analyzer {
-	return &Analyzer{
-		client: client,
+func NewAnalyzer(client api.ClientInterface, opts ...Option) *Analyzer {
+	a := &Analyzer{
+		client:       client,
+		concurrency:  defaultConcurrency,
+		bindingCache: newTTLCache[[]types.Binding](defaultCacheTTL),
+		nameCache:    newTTLCache[string](defaultCacheTTL),
+		logger:       slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	return a
+}
+
+// FlushCache discards all cached bindings and resource names
+func (a *Analyzer) FlushCache() {
+	a.bindingCache.flush()
+	a.nameCache.flush()
+}
+
+// CountWorkers returns the number of workers a full analysis would scan
+func (a *Analyzer) CountWorkers() (int, error) {
+	workers, err := a.client.ListWorkers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list workers: %w", err)
+	}
+	return len(workers), nil
+}
+
+// EstimateAnalysisDuration returns roughly how long a full dependency analysis
+// over workerCount workers will take
+func (a *Analyzer) EstimateAnalysisDuration(workerCount int) time.Duration {
+	return estimatedLatencyPerWorker * time.Duration(workerCount) / time.Duration(a.workerConcurrency())
+}
+
+// workerConcurrency returns the effective analysis pool size
+func (a *Analyzer) workerConcurrency() int {
+	if a.concurrency < 1 {
+		return 1
+	}
+	return a.concurrency
 }
 
 // GetTargetWorkerResources returns the resources for the target worker without dependency analysis
-// This is much faster as it doesn't check other workers, but marks all resources as safe (exclusive)
+// This is much faster as it doesn't check other workers, but marks resources as safe (exclusive)
+// unless a queue's consumers show otherwise
+// The worker's bindings are used as given; only name enrichment makes API calls
 func (a *Analyzer) GetTargetWorkerResources(targetWorker *types.WorkerInfo) ([]types.ResourceUsage, error) {
+	eventlog.AnalysisStarted(a.logger, targetWorker.Name)
 	var result []types.ResourceUsage
+	a.skippedWorkers = nil
+	a.truncated = false
 
 	for _, binding := range targetWorker.Bindings {
-		resourceKey := a.getResourceKey(binding)
+		resourceKey := a.getResourceKey(binding, targetWorker.Name)
 		if resourceKey == "" {
 			continue
 		}
@@ -38,78 +243,158 @@ func (a *Analyzer) GetTargetWorkerResources(targetWorker *types.WorkerInfo) ([]t
 			ResourceType: binding.Type,
 			ResourceName: a.getResourceName(binding),
 			UsedBy:       []string{targetWorker.Name},
-			RiskLevel:    types.RiskLevelSafe, // Assume safe since we're not checking
+		}
+		if id := a.durableObjectNamespaceID(binding, targetWorker.Name); id != "" {
+			usage.ResourceID = id
+			usage.NamespaceResolved = true
 		}
 
 		// Enrich with names if needed
-		usage.ResourceName = a.enrichResourceName(binding, usage.ResourceName)
+		usage.ResourceName = a.enrichResourceName(binding, targetWorker.Name, usage.ResourceName)
+		usage.Location = a.getResourceLocation(binding)
+		usage.Jurisdiction = binding.Jurisdiction
+		usage.StorageBytes, usage.ObjectCount = a.getStorageUsage(binding)
+		usage.KeyCount = a.getKeyCount(binding)
+		usage.TableCount = a.getTableCount(binding)
+		usage.Tags = a.getResourceTags(binding)
+		a.applyQueueDetails(binding, usage)
+		// Queue consumers are known without checking other workers
+		usage.RiskLevel = a.calculateRiskLevel(usage.Users(), targetWorker.Name)
+		if (usage.StorageBytes != nil && *usage.StorageBytes > 0) || usage.HasOtherConsumers(targetWorker.Name) {
+			usage.RiskLevel = types.RiskLevelDanger
+		}
 
 		result = append(result, *usage)
 	}
 
+	eventlog.AnalysisCompleted(a.logger, targetWorker.Name, result, nil)
 	return result, nil
 }
 
 // AnalyzeDependencies analyzes which workers depend on which resources
 func (a *Analyzer) AnalyzeDependencies(targetWorker *types.WorkerInfo, progressCallback ...ProgressCallback) ([]types.ResourceUsage, error) {
+	eventlog.AnalysisStarted(a.logger, targetWorker.Name)
+	resources, err := a.analyzeDependencies(targetWorker, progressCallback...)
+	eventlog.AnalysisCompleted(a.logger, targetWorker.Name, resources, err)
+	return resources, err
+}
+
+// analyzeDependencies runs the analysis for AnalyzeDependencies
+func (a *Analyzer) analyzeDependencies(targetWorker *types.WorkerInfo, progressCallback ...ProgressCallback) ([]types.ResourceUsage, error) {
 	// Get callback if provided
 	var callback ProgressCallback
 	if len(progressCallback) > 0 {
 		callback = progressCallback[0]
 	}
 
+	a.skippedWorkers = nil
+	a.truncated = false
+
 	// Get all workers in the account
 	allWorkers, err := a.client.ListWorkers()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list workers: %w", err)
 	}
 
+	// Scan the most recently active workers first when capped
+	if a.maxWorkers > 0 && len(allWorkers) > a.maxWorkers {
+		sort.SliceStable(allWorkers, func(i, j int) bool {
+			return allWorkers[i].ModifiedOn.After(allWorkers[j].ModifiedOn)
+		})
+		allWorkers = allWorkers[:a.maxWorkers]
+		a.truncated = true
+	}
+
 	totalWorkers := len(allWorkers)
 
 	// Build a map of resources to workers that use them
 	resourceMap := make(map[string]*types.ResourceUsage)
-
-	// Process all workers to find resource usage
-	for i, worker := range allWorkers {
-		// Report progress if callback is provided
-		if callback != nil {
-			callback(i+1, totalWorkers, worker.Name)
+	var skipped []string
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		completed int
+	)
+	sem := make(chan struct{}, a.workerConcurrency())
+
+	// Process all workers concurrently to find resource usage
+	ctx := a.client.Context()
+
+	for _, worker := range allWorkers {
+		// Stop scheduling work once the run has been cancelled or timed out
+		if ctx.Err() != nil {
+			break
 		}
 
-		// Get full worker details with bindings
-		fullWorker, err := a.client.GetWorker(worker.Name)
-		if err != nil {
-			// Skip workers we can't read
-			continue
-		}
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(workerName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Only bindings are needed here, so skip the full worker lookup
+			bindings, err := a.getWorkerBindings(workerName)
 
-		// Process each binding
-		for _, binding := range fullWorker.Bindings {
-			resourceKey := a.getResourceKey(binding)
-			if resourceKey == "" {
-				continue
+			mu.Lock()
+			defer mu.Unlock()
+
+			completed++
+			// Report progress if callback is provided
+			if callback != nil {
+				callback(completed, totalWorkers, workerName)
+			}
+
+			if err != nil {
+				// Skip workers we can't read, their bindings are unknown
+				skipped = append(skipped, workerName)
+				return
 			}
 
-			// Initialize resource usage if not exists
-			if _, exists := resourceMap[resourceKey]; !exists {
-				resourceMap[resourceKey] = &types.ResourceUsage{
-					ResourceID:   a.getResourceID(binding),
-					ResourceType: binding.Type,
-					ResourceName: a.getResourceName(binding),
-					UsedBy:       []string{},
+			// Process each binding
+			for _, binding := range bindings {
+				resourceKey := a.getResourceKey(binding, workerName)
+				if resourceKey == "" {
+					continue
 				}
+
+				// Initialize resource usage if not exists
+				if _, exists := resourceMap[resourceKey]; !exists {
+					resourceMap[resourceKey] = &types.ResourceUsage{
+						ResourceID:   a.getResourceID(binding),
+						ResourceType: binding.Type,
+						ResourceName: a.getResourceName(binding),
+						UsedBy:       []string{},
+					}
+				}
+
+				// Add this worker to the list of users
+				resourceMap[resourceKey].UsedBy = append(resourceMap[resourceKey].UsedBy, workerName)
 			}
+		}(worker.Name)
+	}
 
-			// Add this worker to the list of users
-			resourceMap[resourceKey].UsedBy = append(resourceMap[resourceKey].UsedBy, worker.Name)
-		}
+	wg.Wait()
+
+	// Workers skipped because of cancellation would make shared resources look
+	// exclusive, so an interrupted analysis can't be used
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("dependency analysis interrupted: %w", err)
 	}
 
+	// Workers finish in any order, keep the output stable
+	for _, usage := range resourceMap {
+		sort.Strings(usage.UsedBy)
+	}
+	sort.Strings(skipped)
+	a.skippedWorkers = skipped
+
 	// Now build the list of resources used by the target worker
 	var result []types.ResourceUsage
 
 	for _, binding := range targetWorker.Bindings {
-		resourceKey := a.getResourceKey(binding)
+		resourceKey := a.getResourceKey(binding, targetWorker.Name)
 		if resourceKey == "" {
 			continue
 		}
@@ -126,10 +411,32 @@ func (a *Analyzer) AnalyzeDependencies(targetWorker *types.WorkerInfo, progressC
 		}
 
 		// Enrich with names if needed
-		usage.ResourceName = a.enrichResourceName(binding, usage.ResourceName)
+		usage.ResourceName = a.enrichResourceName(binding, targetWorker.Name, usage.ResourceName)
+		usage.Location = a.getResourceLocation(binding)
+		usage.Jurisdiction = binding.Jurisdiction
+		usage.StorageBytes, usage.ObjectCount = a.getStorageUsage(binding)
+		usage.KeyCount = a.getKeyCount(binding)
+		usage.TableCount = a.getTableCount(binding)
+		usage.Tags = a.getResourceTags(binding)
+		a.applyQueueDetails(binding, usage)
+		if id := a.durableObjectNamespaceID(binding, targetWorker.Name); id != "" {
+			usage.ResourceID = id
+			usage.NamespaceResolved = true
+		}
+
+		// Calculate risk level, a worker consuming a queue shares it too
+		usage.RiskLevel = a.calculateRiskLevel(usage.Users(), targetWorker.Name)
 
-		// Calculate risk level
-		usage.RiskLevel = a.calculateRiskLevel(usage.UsedBy, targetWorker.Name)
+		// A skipped worker may also use a resource that looks exclusive
+		if usage.RiskLevel == types.RiskLevelSafe && len(skipped) > 0 {
+			usage.RiskLevel = types.RiskLevelUnknown
+		}
+
+		// Deleting a bucket that holds data loses it, shared or not, and a
+		// queue another worker consumes breaks that worker
+		if (usage.StorageBytes != nil && *usage.StorageBytes > 0) || usage.HasOtherConsumers(targetWorker.Name) {
+			usage.RiskLevel = types.RiskLevelDanger
+		}
 
 		result = append(result, *usage)
 	}
@@ -137,8 +444,26 @@ func (a *Analyzer) AnalyzeDependencies(targetWorker *types.WorkerInfo, progressC
 	return result, nil
 }
 
-// getResourceKey returns a unique key for a resource
-func (a *Analyzer) getResourceKey(binding types.Binding) string {
+// getWorkerBindings returns a worker's bindings, from the cache when fresh
+func (a *Analyzer) getWorkerBindings(workerName string) ([]types.Binding, error) {
+	if bindings, ok := a.bindingCache.get(workerName); ok {
+		return bindings, nil
+	}
+
+	bindings, err := a.client.GetWorkerBindings(workerName)
+	if err != nil {
+		return nil, err
+	}
+
+	a.bindingCache.set(workerName, bindings)
+	return bindings, nil
+}
+
+// getResourceKey returns a unique key for a resource bound by workerName. A
+// Durable Object binding without a script name refers to a class in the
+// worker itself, so it is keyed by that worker, the same as another worker's
+// binding to the class.
+func (a *Analyzer) getResourceKey(binding types.Binding, workerName string) string {
 	switch binding.Type {
 	case types.BindingTypeKV:
 		return fmt.Sprintf("kv:%s", binding.NamespaceID)
@@ -147,11 +472,23 @@ func (a *Analyzer) getResourceKey(binding types.Binding) string {
 	case types.BindingTypeD1:
 		return fmt.Sprintf("d1:%s", binding.DatabaseID)
 	case types.BindingTypeDurableObject:
-		return fmt.Sprintf("do:%s:%s", binding.ClassName, binding.ScriptName)
+		script := binding.ScriptName
+		if script == "" {
+			script = workerName
+		}
+		return fmt.Sprintf("do:%s:%s", binding.ClassName, script)
 	case types.BindingTypeService:
 		return fmt.Sprintf("service:%s", binding.ScriptName)
 	case types.BindingTypeQueue:
 		return fmt.Sprintf("queue:%s", binding.QueueName)
+	case types.BindingTypeHyperdrive:
+		return fmt.Sprintf("hyperdrive:%s", binding.ConfigID)
+	case types.BindingTypeVectorize:
+		return fmt.Sprintf("vectorize:%s", binding.IndexName)
+	case types.BindingTypeMTLS:
+		return fmt.Sprintf("mtls:%s", binding.CertificateID)
+	case types.BindingTypeAnalyticsEngine:
+		return fmt.Sprintf("analytics_engine:%s", binding.DatasetName)
 	default:
 		return ""
 	}
@@ -172,6 +509,14 @@ func (a *Analyzer) getResourceID(binding types.Binding) string {
 		return binding.ScriptName
 	case types.BindingTypeQueue:
 		return binding.QueueName
+	case types.BindingTypeHyperdrive:
+		return binding.ConfigID
+	case types.BindingTypeVectorize:
+		return binding.IndexName
+	case types.BindingTypeMTLS:
+		return binding.CertificateID
+	case types.BindingTypeAnalyticsEngine:
+		return binding.DatasetName
 	default:
 		return binding.Name
 	}
@@ -192,29 +537,191 @@ func (a *Analyzer) getResourceName(binding types.Binding) string {
 		return binding.ScriptName
 	case types.BindingTypeQueue:
 		return binding.QueueName
+	case types.BindingTypeHyperdrive:
+		return binding.Name // Will enrich later
+	case types.BindingTypeVectorize:
+		return binding.IndexName
+	case types.BindingTypeMTLS:
+		return binding.CertificateID
+	case types.BindingTypeAnalyticsEngine:
+		return binding.DatasetName
 	default:
 		return binding.Name
 	}
 }
 
 // enrichResourceName fetches the actual resource name from the API
-func (a *Analyzer) enrichResourceName(binding types.Binding, currentName string) string {
+func (a *Analyzer) enrichResourceName(binding types.Binding, workerName, currentName string) string {
 	if currentName != "" && currentName != binding.Name {
 		return currentName
 	}
 
+	if a.noEnrichment {
+		// Fall back to the resource ID rather than the binding's variable name
+		if id := a.getResourceID(binding); id != "" {
+			return id
+		}
+		return currentName
+	}
+
+	key := a.getResourceKey(binding, workerName)
+	if name, ok := a.nameCache.get(key); ok {
+		return name
+	}
+
+	var (
+		name string
+		err  error
+	)
 	switch binding.Type {
 	case types.BindingTypeKV:
-		if title, err := a.client.GetKVNamespaceTitle(binding.NamespaceID); err == nil {
-			return title
-		}
+		name, err = a.client.GetKVNamespaceTitle(binding.NamespaceID)
 	case types.BindingTypeD1:
-		if name, err := a.client.GetD1DatabaseName(binding.DatabaseID); err == nil {
-			return name
+		name, err = a.client.GetD1DatabaseName(binding.DatabaseID)
+	case types.BindingTypeHyperdrive:
+		name, err = a.client.GetHyperdriveConfigName(binding.ConfigID)
+	default:
+		return currentName
+	}
+
+	if err != nil || name == "" {
+		return currentName
+	}
+
+	a.nameCache.set(key, name)
+
+	return name
+}
+
+// durableObjectNamespaceID looks up the namespace backing a Durable Object
+// binding by class and script. Only classes defined by the worker itself are
+// resolved, a namespace owned by another worker must not be deleted with this
+// one. Returns "" when the namespace can't be found.
+func (a *Analyzer) durableObjectNamespaceID(binding types.Binding, workerName string) string {
+	if binding.Type != types.BindingTypeDurableObject {
+		return ""
+	}
+
+	// Bindings without a script name refer to a class in the worker itself
+	script := binding.ScriptName
+	if script == "" {
+		script = workerName
+	}
+	if script != workerName {
+		return ""
+	}
+
+	key := fmt.Sprintf("do-namespace:%s:%s", binding.ClassName, script)
+	if id, ok := a.nameCache.get(key); ok {
+		return id
+	}
+
+	namespaces, err := a.client.ListDurableObjectNamespaces()
+	if err != nil {
+		return ""
+	}
+
+	for _, ns := range namespaces {
+		if ns.Class == binding.ClassName && ns.Script == script {
+			a.nameCache.set(key, ns.ID)
+			return ns.ID
 		}
 	}
+	return ""
+}
+
+// getResourceLocation fetches the storage location of the resource where
+// applicable
+func (a *Analyzer) getResourceLocation(binding types.Binding) string {
+	if a.noEnrichment || binding.Type != types.BindingTypeR2 {
+		return ""
+	}
+
+	location, err := a.client.GetR2BucketLocation(binding.BucketName, binding.Jurisdiction)
+	if err != nil {
+		return ""
+	}
+	return location
+}
+
+// getStorageUsage fetches how much data, and how many objects, an R2 bucket
+// holds. The size is nil for other resource types and buckets whose usage
+// can't be read.
+func (a *Analyzer) getStorageUsage(binding types.Binding) (*int64, int) {
+	if a.noEnrichment || binding.Type != types.BindingTypeR2 {
+		return nil, 0
+	}
+
+	size, objects, err := a.client.GetR2BucketStorageUsage(binding.BucketName, binding.Jurisdiction)
+	if err != nil {
+		return nil, 0
+	}
+	return &size, objects
+}
+
+// applyQueueDetails records the consumers and message backlog of a queue.
+// Other resource types, and queues that can't be looked up, are left alone.
+func (a *Analyzer) applyQueueDetails(binding types.Binding, usage *types.ResourceUsage) {
+	if a.noEnrichment || binding.Type != types.BindingTypeQueue {
+		return
+	}
+
+	queue, err := a.client.GetQueueDetails(binding.QueueName)
+	if err != nil {
+		return
+	}
+	usage.Consumers = queue.Consumers
+	usage.Backlog = queue.Backlog
+}
+
+// getKeyCount counts the keys in a KV namespace. It returns nil for other
+// resource types and namespaces whose keys can't be listed. Unlike a bucket
+// holding data, a namespace's keys don't raise its risk level: it stays a
+// matter of sharing, and an empty namespace loses nothing on deletion.
+func (a *Analyzer) getKeyCount(binding types.Binding) *int {
+	if a.noEnrichment || binding.Type != types.BindingTypeKV {
+		return nil
+	}
+
+	count, err := a.client.GetKVNamespaceKeyCount(binding.NamespaceID)
+	if err != nil {
+		return nil
+	}
+	return &count
+}
+
+// getTableCount counts the tables in a D1 database. It returns nil for other
+// resource types and databases that can't be queried.
+func (a *Analyzer) getTableCount(binding types.Binding) *int {
+	if a.noEnrichment || binding.Type != types.BindingTypeD1 {
+		return nil
+	}
+
+	count, err := a.client.GetD1DatabaseTableCount(binding.DatabaseID)
+	if err != nil {
+		return nil
+	}
+	return &count
+}
+
+// getResourceTags fetches the dashboard tags of the resource where applicable.
+// Tags are always fetched when filtering on them.
+func (a *Analyzer) getResourceTags(binding types.Binding) []string {
+	if a.noEnrichment && a.tagFilter == "" {
+		return nil
+	}
+
+	switch binding.Type {
+	case types.BindingTypeKV, types.BindingTypeR2, types.BindingTypeD1:
+	default:
+		return nil
+	}
 
-	return currentName
+	tags, err := a.client.GetResourceTags(binding.Type, a.getResourceID(binding))
+	if err != nil {
+		return nil
+	}
+	return tags
 }
 
 // calculateRiskLevel determines the risk level based on usage
@@ -235,27 +742,146 @@ func (a *Analyzer) calculateRiskLevel(usedBy []string, targetWorker string) type
 	return types.RiskLevelDanger // Used by 3+ workers
 }
 
+// PlanOptions control which analysed resources CreateDeletionPlan puts in
+// the plan
+type PlanOptions struct {
+	// ExclusiveOnly leaves out resources shared with other workers
+	ExclusiveOnly bool
+	// SkipEmpty keeps KV namespaces without keys, D1 databases without
+	// tables and R2 buckets without data
+	SkipEmpty bool
+}
+
 // CreateDeletionPlan creates a deletion plan based on analysis
-func (a *Analyzer) CreateDeletionPlan(worker *types.WorkerInfo, resources []types.ResourceUsage, exclusiveOnly bool) *types.DeletionPlan {
+func (a *Analyzer) CreateDeletionPlan(worker *types.WorkerInfo, resources []types.ResourceUsage, opts PlanOptions) *types.DeletionPlan {
 	plan := &types.DeletionPlan{
 		Worker:              *worker,
 		ResourcesToDelete:   []types.ResourceUsage{},
 		HasSharedResources:  false,
-		DeleteExclusiveOnly: exclusiveOnly,
+		DeleteExclusiveOnly: opts.ExclusiveOnly,
+		IncludeTypes:        a.include,
+		TagFilter:           a.tagFilter,
+		RegionFilter:        a.regionFilter,
+		SkipWorkerDeletion:  a.keepWorker,
+		SkippedWorkers:      a.skippedWorkers,
+		AnalysisTruncated:   a.truncated,
+		DeleteRoutes:        a.deleteRoutes && !a.keepWorker,
+		DeleteSecrets:       a.deleteSecrets && a.keepWorker,
+		EmptyR2Buckets:      a.emptyR2,
 	}
 
+	// Routes only matter when the worker goes, a kept worker keeps serving
+	// them. Listing them walks every zone, so skip it otherwise.
+	if !a.keepWorker {
+		routes, err := a.client.GetWorkerRoutes(worker.Name)
+		plan.Routes = routes
+		if err != nil {
+			plan.RouteLookupError = err.Error()
+		}
+	}
+
+	// A worker can bind the same resource under several names, list it once
+	seen := make(map[string]bool)
 	for _, resource := range resources {
-		if resource.RiskLevel != types.RiskLevelSafe {
+		key := fmt.Sprintf("%s:%s", resource.ResourceType, resource.ResourceID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if !a.isIncluded(resource) || !a.hasTag(resource) {
+			continue
+		}
+
+		if slices.Contains(a.skipTypes, resource.ResourceType) {
+			plan.ResourcesSkippedByType = append(plan.ResourcesSkippedByType, resource)
+			continue
+		}
+
+		if a.isExcluded(resource) || !a.inRegion(resource) {
+			plan.ResourcesExcluded = append(plan.ResourcesExcluded, resource.ResourceName)
+			continue
+		}
+
+		if opts.SkipEmpty && isEmpty(resource) {
+			plan.ResourcesSkippedEmpty = append(plan.ResourcesSkippedEmpty, resource)
+			continue
+		}
+
+		// Risk levels also flag buckets holding data, only sharing counts here
+		shared := resource.IsShared(worker.Name)
+		if shared {
 			plan.HasSharedResources = true
 		}
 
 		// If exclusive only mode, skip shared resources
-		if exclusiveOnly && resource.RiskLevel != types.RiskLevelSafe {
+		if opts.ExclusiveOnly && shared {
 			continue
 		}
 
 		plan.ResourcesToDelete = append(plan.ResourcesToDelete, resource)
 	}
 
+	if a.truncated {
+		plan.AnalysisLimit = a.maxWorkers
+	}
+
 	return plan
 }
+
+// isEmpty reports whether a KV namespace, D1 database or R2 bucket was
+// counted as holding nothing. A resource that couldn't be counted isn't
+// empty; other resource types never are.
+func isEmpty(resource types.ResourceUsage) bool {
+	switch resource.ResourceType {
+	case types.BindingTypeKV:
+		return resource.KeyCount != nil && *resource.KeyCount == 0
+	case types.BindingTypeD1:
+		return resource.TableCount != nil && *resource.TableCount == 0
+	case types.BindingTypeR2:
+		return resource.StorageBytes != nil && *resource.StorageBytes == 0
+	default:
+		return false
+	}
+}
+
+// isExcluded reports whether a resource matches the exclude list by ID or name
+func (a *Analyzer) isExcluded(resource types.ResourceUsage) bool {
+	for _, entry := range a.exclude {
+		if entry == resource.ResourceID || entry == resource.ResourceName {
+			return true
+		}
+	}
+	return false
+}
+
+// inRegion reports whether a resource passes the region filter. The
+// jurisdiction comes from the binding, so it is known even without enrichment.
+func (a *Analyzer) inRegion(resource types.ResourceUsage) bool {
+	if a.regionFilter == "" || resource.ResourceType != types.BindingTypeR2 {
+		return true
+	}
+	jurisdiction := resource.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = "default"
+	}
+	return strings.EqualFold(jurisdiction, a.regionFilter)
+}
+
+// hasTag reports whether a resource passes the tag filter
+func (a *Analyzer) hasTag(resource types.ResourceUsage) bool {
+	return a.tagFilter == "" || slices.Contains(resource.Tags, a.tagFilter)
+}
+
+// isIncluded reports whether a resource's type passes the include filter
+func (a *Analyzer) isIncluded(resource types.ResourceUsage) bool {
+	if len(a.include) == 0 {
+		return true
+	}
+	for _, t := range a.include {
+		if t == resource.ResourceType {
+			return true
+		}
+	}
+	return false
+}
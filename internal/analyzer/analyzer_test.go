@@ -0,0 +1,322 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/internal/api/apitest"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// newAccount returns a mock account whose workers have the given bindings
+func newAccount(bindings map[string][]types.Binding) *apitest.MockClient {
+	client := apitest.NewMockClient()
+	client.Bindings = bindings
+	for name := range bindings {
+		client.Workers = append(client.Workers, types.WorkerInfo{Name: name})
+	}
+	return client
+}
+
+func kvBinding(id string) types.Binding {
+	return types.Binding{Type: types.BindingTypeKV, Name: "CACHE", NamespaceID: id}
+}
+
+func TestAnalyzeDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		others   int
+		wantRisk types.RiskLevel
+	}{
+		{"exclusive", 0, types.RiskLevelSafe},
+		{"shared with one worker", 1, types.RiskLevelCaution},
+		{"shared with two workers", 2, types.RiskLevelCaution},
+		{"shared with three workers", 3, types.RiskLevelDanger},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings := map[string][]types.Binding{"app": {kvBinding("ns1")}}
+			for i := 0; i < tt.others; i++ {
+				bindings[fmt.Sprintf("other-%d", i)] = []types.Binding{kvBinding("ns1")}
+			}
+			client := newAccount(bindings)
+			a := NewAnalyzer(client, WithoutEnrichment())
+
+			resources, err := a.AnalyzeDependencies(&types.WorkerInfo{Name: "app", Bindings: bindings["app"]})
+			if err != nil {
+				t.Fatalf("AnalyzeDependencies() error = %v", err)
+			}
+			if len(resources) != 1 {
+				t.Fatalf("got %d resources, want 1", len(resources))
+			}
+			if got := resources[0].RiskLevel; got != tt.wantRisk {
+				t.Errorf("RiskLevel = %v, want %v", got, tt.wantRisk)
+			}
+			if got := len(resources[0].UsedBy); got != tt.others+1 {
+				t.Errorf("UsedBy has %d workers, want %d", got, tt.others+1)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDependenciesSkippedWorker(t *testing.T) {
+	target := &types.WorkerInfo{Name: "app", Bindings: []types.Binding{kvBinding("ns1")}}
+	client := newAccount(map[string][]types.Binding{"app": target.Bindings, "unreadable": nil})
+	client.Errors["GetWorkerBindings"] = errors.New("forbidden")
+
+	// The target's bindings come from the cache, every other lookup fails
+	a := NewAnalyzer(client, WithoutEnrichment(), WithWorkerCache(target))
+
+	resources, err := a.AnalyzeDependencies(target)
+	if err != nil {
+		t.Fatalf("AnalyzeDependencies() error = %v", err)
+	}
+	if got := resources[0].RiskLevel; got != types.RiskLevelUnknown {
+		t.Errorf("RiskLevel = %v, want %v", got, types.RiskLevelUnknown)
+	}
+}
+
+func TestAnalyzeDependenciesDurableObjectConsumer(t *testing.T) {
+	// The owner binds its own class without a script name, the consumer
+	// names the owner
+	owner := []types.Binding{{Type: types.BindingTypeDurableObject, Name: "COUNTER", ClassName: "Counter"}}
+	consumer := []types.Binding{{Type: types.BindingTypeDurableObject, Name: "COUNTER", ClassName: "Counter", ScriptName: "app"}}
+	client := newAccount(map[string][]types.Binding{"app": owner, "client": consumer})
+	client.DurableObjectNamespaces = []types.DurableObjectNamespace{{ID: "ns-counter", Script: "app", Class: "Counter"}}
+	a := NewAnalyzer(client, WithoutEnrichment())
+
+	resources, err := a.AnalyzeDependencies(&types.WorkerInfo{Name: "app", Bindings: owner})
+	if err != nil {
+		t.Fatalf("AnalyzeDependencies() error = %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(resources))
+	}
+	ns := resources[0]
+	if ns.RiskLevel == types.RiskLevelSafe || !ns.IsShared("app") {
+		t.Errorf("RiskLevel = %v, want the namespace shared with client", ns.RiskLevel)
+	}
+	if !slices.Equal(ns.UsedBy, []string{"app", "client"}) {
+		t.Errorf("UsedBy = %v, want [app client]", ns.UsedBy)
+	}
+	if ns.ResourceID != "ns-counter" || !ns.NamespaceResolved {
+		t.Errorf("ResourceID = %q, NamespaceResolved = %v, want the owner's namespace", ns.ResourceID, ns.NamespaceResolved)
+	}
+}
+
+func TestAnalyzeDependenciesDangerIsNotSharing(t *testing.T) {
+	bindings := []types.Binding{
+		{Type: types.BindingTypeR2, Name: "UPLOADS", BucketName: "uploads"},
+		{Type: types.BindingTypeQueue, Name: "JOBS", QueueName: "jobs"},
+	}
+	client := newAccount(map[string][]types.Binding{"app": bindings})
+	client.StorageBytes = map[string]int64{"uploads": 4096}
+	client.ObjectCounts = map[string]int{"uploads": 2}
+	client.Queues = map[string]*types.QueueDetails{"jobs": {Name: "jobs", Consumers: []string{"app", "consumer"}}}
+	worker := &types.WorkerInfo{Name: "app", Bindings: bindings}
+	a := NewAnalyzer(client)
+
+	resources, err := a.AnalyzeDependencies(worker)
+	if err != nil {
+		t.Fatalf("AnalyzeDependencies() error = %v", err)
+	}
+	risks := map[string]types.RiskLevel{}
+	for _, r := range resources {
+		risks[r.ResourceName] = r.RiskLevel
+	}
+	// Deleting a bucket holding data loses it, deleting a queue another
+	// worker consumes breaks that worker
+	if risks["uploads"] != types.RiskLevelDanger {
+		t.Errorf("uploads RiskLevel = %v, want %v", risks["uploads"], types.RiskLevelDanger)
+	}
+	if risks["jobs"] != types.RiskLevelDanger {
+		t.Errorf("jobs RiskLevel = %v, want %v", risks["jobs"], types.RiskLevelDanger)
+	}
+
+	// Only the queue is shared, the bucket is still exclusive to app
+	plan := a.CreateDeletionPlan(worker, resources, PlanOptions{ExclusiveOnly: true})
+	if len(plan.ResourcesToDelete) != 1 || plan.ResourcesToDelete[0].ResourceName != "uploads" {
+		t.Errorf("ResourcesToDelete = %v, want only uploads", plan.ResourcesToDelete)
+	}
+	if len(plan.NonEmptyBuckets()) != 1 {
+		t.Errorf("NonEmptyBuckets() = %v, want uploads", plan.NonEmptyBuckets())
+	}
+}
+
+func TestCreateDeletionPlan(t *testing.T) {
+	worker := &types.WorkerInfo{Name: "app"}
+	resources := []types.ResourceUsage{
+		{ResourceID: "ns1", ResourceType: types.BindingTypeKV, ResourceName: "exclusive", UsedBy: []string{"app"}, RiskLevel: types.RiskLevelSafe},
+		{ResourceID: "ns2", ResourceType: types.BindingTypeKV, ResourceName: "shared", UsedBy: []string{"app", "other"}, RiskLevel: types.RiskLevelCaution},
+		{ResourceID: "ns1", ResourceType: types.BindingTypeKV, ResourceName: "exclusive", UsedBy: []string{"app"}, RiskLevel: types.RiskLevelSafe},
+	}
+
+	tests := []struct {
+		name      string
+		opts      PlanOptions
+		wantNames []string
+	}{
+		{"all resources", PlanOptions{}, []string{"exclusive", "shared"}},
+		{"exclusive only", PlanOptions{ExclusiveOnly: true}, []string{"exclusive"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnalyzer(apitest.NewMockClient())
+			plan := a.CreateDeletionPlan(worker, resources, tt.opts)
+
+			if !plan.HasSharedResources {
+				t.Error("HasSharedResources = false, want true")
+			}
+			var names []string
+			for _, r := range plan.ResourcesToDelete {
+				names = append(names, r.ResourceName)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("ResourcesToDelete = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestCreateDeletionPlanRoutes(t *testing.T) {
+	worker := &types.WorkerInfo{Name: "app"}
+	route := types.WorkerRoute{ID: "r1", Pattern: "example.com/*", ZoneID: "z1", ZoneName: "example.com"}
+
+	t.Run("partial lookup", func(t *testing.T) {
+		client := apitest.NewMockClient()
+		client.Routes = map[string][]types.WorkerRoute{"app": {route}}
+		client.Errors = map[string]error{"GetWorkerRoutes": errors.New("failed to list routes for zone other.com")}
+
+		plan := NewAnalyzer(client, WithDeleteRoutes()).CreateDeletionPlan(worker, nil, PlanOptions{})
+		if len(plan.Routes) != 1 {
+			t.Errorf("Routes = %v, want the routes that were found", plan.Routes)
+		}
+		if plan.RouteLookupError == "" {
+			t.Error("RouteLookupError is empty, want the zone failure")
+		}
+		if err := plan.Validate(); !errors.Is(err, types.ErrInvalidPlan) {
+			t.Errorf("Validate() error = %v, want ErrInvalidPlan with --delete-routes", err)
+		}
+	})
+
+	t.Run("kept worker", func(t *testing.T) {
+		client := apitest.NewMockClient()
+		client.Routes = map[string][]types.WorkerRoute{"app": {route}}
+
+		plan := NewAnalyzer(client, WithSkipWorkerDeletion()).CreateDeletionPlan(worker, nil, PlanOptions{})
+		if got := client.CallCount("GetWorkerRoutes"); got != 0 {
+			t.Errorf("GetWorkerRoutes called %d times, want 0", got)
+		}
+		if len(plan.Routes) != 0 {
+			t.Errorf("Routes = %v, want none", plan.Routes)
+		}
+	})
+}
+
+func TestCreateDeletionPlanSkipEmpty(t *testing.T) {
+	bindings := []types.Binding{
+		kvBinding("ns1"),
+		{Type: types.BindingTypeR2, Name: "UPLOADS", BucketName: "uploads"},
+	}
+
+	tests := []struct {
+		name        string
+		errors      map[string]error
+		wantSkipped int
+	}{
+		{"counted empty", nil, 2},
+		{"counts failed", map[string]error{
+			"GetKVNamespaceKeyCount":  errors.New("list failed"),
+			"GetR2BucketStorageUsage": errors.New("usage failed"),
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newAccount(map[string][]types.Binding{"app": bindings})
+			client.Errors = tt.errors
+			worker := &types.WorkerInfo{Name: "app", Bindings: bindings}
+			a := NewAnalyzer(client)
+
+			resources, err := a.AnalyzeDependencies(worker)
+			if err != nil {
+				t.Fatalf("AnalyzeDependencies() error = %v", err)
+			}
+			// A resource whose contents couldn't be counted may hold data
+			plan := a.CreateDeletionPlan(worker, resources, PlanOptions{SkipEmpty: true})
+			if len(plan.ResourcesSkippedEmpty) != tt.wantSkipped {
+				t.Errorf("ResourcesSkippedEmpty = %v, want %d", plan.ResourcesSkippedEmpty, tt.wantSkipped)
+			}
+			if len(plan.ResourcesToDelete)+len(plan.ResourcesSkippedEmpty) != 2 {
+				t.Errorf("ResourcesToDelete = %v, want the rest of the 2 resources", plan.ResourcesToDelete)
+			}
+		})
+	}
+}
+
+func TestCreateDeletionPlanRegionFilter(t *testing.T) {
+	bindings := []types.Binding{
+		{Type: types.BindingTypeR2, Name: "EU", BucketName: "eu-uploads", Jurisdiction: "eu"},
+		{Type: types.BindingTypeR2, Name: "UPLOADS", BucketName: "uploads"},
+	}
+	client := newAccount(map[string][]types.Binding{"app": bindings})
+	client.Locations = map[string]string{"eu-uploads": "WEUR", "uploads": "WEUR"}
+	worker := &types.WorkerInfo{Name: "app", Bindings: bindings}
+
+	tests := []struct {
+		region    string
+		wantNames []string
+	}{
+		{"eu", []string{"eu-uploads"}},
+		{"default", []string{"uploads"}},
+		{"fedramp", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			a := NewAnalyzer(client, WithRegionFilter(tt.region))
+			resources, err := a.AnalyzeDependencies(worker)
+			if err != nil {
+				t.Fatalf("AnalyzeDependencies() error = %v", err)
+			}
+
+			plan := a.CreateDeletionPlan(worker, resources, PlanOptions{})
+			var names []string
+			for _, r := range plan.ResourcesToDelete {
+				names = append(names, r.ResourceName)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("ResourcesToDelete = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+// BenchmarkAnalyzeDependencies compares a sequential analysis with the default
+// concurrency against an account of 50 workers, each API call taking 2ms
+func BenchmarkAnalyzeDependencies(b *testing.B) {
+	bindings := map[string][]types.Binding{}
+	for i := range 50 {
+		bindings[fmt.Sprintf("worker-%d", i)] = []types.Binding{kvBinding(fmt.Sprintf("ns%d", i%5))}
+	}
+	client := newAccount(bindings)
+	client.Latency = 2 * time.Millisecond
+	worker := &types.WorkerInfo{Name: "worker-0", Bindings: bindings["worker-0"]}
+
+	for _, concurrency := range []int{1, defaultConcurrency} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for b.Loop() {
+				// A fresh analyzer each time, so the binding cache starts empty
+				a := NewAnalyzer(client, WithConcurrency(concurrency), WithoutEnrichment())
+				if _, err := a.AnalyzeDependencies(worker); err != nil {
+					b.Fatalf("AnalyzeDependencies() error = %v", err)
+				}
+			}
+		})
+	}
+}
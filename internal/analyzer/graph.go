@@ -0,0 +1,181 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// NodeKind distinguishes workers from resources in a ResourceGraph
+type NodeKind string
+
+const (
+	NodeWorker   NodeKind = "worker"
+	NodeResource NodeKind = "resource"
+)
+
+// GraphNode is a worker or a resource in a ResourceGraph
+type GraphNode struct {
+	ID           string            `json:"id"`
+	Kind         NodeKind          `json:"kind"`
+	Label        string            `json:"label"`
+	ResourceType types.BindingType `json:"resource_type,omitempty"`
+	RiskLevel    types.RiskLevel   `json:"risk_level"`
+}
+
+// GraphEdge is a binding from a worker to a resource
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ResourceGraph shows a worker's resources and every worker bound to them
+type ResourceGraph struct {
+	Worker string      `json:"worker"`
+	Nodes  []GraphNode `json:"nodes"`
+	Edges  []GraphEdge `json:"edges"`
+}
+
+// GetResourceGraph analyzes the worker's dependencies and returns them as a
+// graph of workers and resources
+func (a *Analyzer) GetResourceGraph(worker *types.WorkerInfo) (*ResourceGraph, error) {
+	resources, err := a.AnalyzeDependencies(worker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze dependencies: %w", err)
+	}
+
+	graph := &ResourceGraph{Worker: worker.Name}
+	graph.Nodes = append(graph.Nodes, GraphNode{
+		ID:    workerNodeID(worker.Name),
+		Kind:  NodeWorker,
+		Label: worker.Name,
+	})
+
+	others := make(map[string]bool)
+	for _, resource := range resources {
+		id := resourceNodeID(resource)
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:           id,
+			Kind:         NodeResource,
+			Label:        resource.ResourceName,
+			ResourceType: resource.ResourceType,
+			RiskLevel:    resource.RiskLevel,
+		})
+
+		// The target worker binds every resource, even if the analysis
+		// didn't list it among the users
+		graph.Edges = append(graph.Edges, GraphEdge{From: workerNodeID(worker.Name), To: id})
+		for _, user := range resource.UsedBy {
+			if user == worker.Name {
+				continue
+			}
+			others[user] = true
+			graph.Edges = append(graph.Edges, GraphEdge{From: workerNodeID(user), To: id})
+		}
+	}
+
+	names := make([]string, 0, len(others))
+	for name := range others {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:    workerNodeID(name),
+			Kind:  NodeWorker,
+			Label: name,
+		})
+	}
+
+	return graph, nil
+}
+
+func workerNodeID(name string) string {
+	return "worker:" + name
+}
+
+func resourceNodeID(resource types.ResourceUsage) string {
+	return fmt.Sprintf("%s:%s", resource.ResourceType, resource.ResourceID)
+}
+
+// RenderDOT renders the graph in Graphviz DOT format
+func (g *ResourceGraph) RenderDOT() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("digraph %s {\n", strconv.Quote(g.Worker)))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		switch {
+		case node.Kind == NodeResource:
+			label := fmt.Sprintf("%s\n%s", node.ResourceType, node.Label)
+			b.WriteString(fmt.Sprintf("  %s [label=%s, shape=cylinder];\n", strconv.Quote(node.ID), strconv.Quote(label)))
+		case node.Label == g.Worker:
+			b.WriteString(fmt.Sprintf("  %s [label=%s, style=bold];\n", strconv.Quote(node.ID), strconv.Quote(node.Label)))
+		default:
+			b.WriteString(fmt.Sprintf("  %s [label=%s];\n", strconv.Quote(node.ID), strconv.Quote(node.Label)))
+		}
+	}
+
+	for _, edge := range g.Edges {
+		b.WriteString(fmt.Sprintf("  %s -> %s;\n", strconv.Quote(edge.From), strconv.Quote(edge.To)))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RenderASCII renders the graph as a tree of the worker's resources, each
+// listing the other workers bound to it
+func (g *ResourceGraph) RenderASCII() string {
+	var b strings.Builder
+
+	b.WriteString(g.Worker)
+	b.WriteString("\n")
+
+	// Other workers bound to each resource, in edge order
+	sharedWith := make(map[string][]string)
+	labels := make(map[string]string)
+	for _, node := range g.Nodes {
+		labels[node.ID] = node.Label
+	}
+	for _, edge := range g.Edges {
+		if edge.From != workerNodeID(g.Worker) {
+			sharedWith[edge.To] = append(sharedWith[edge.To], labels[edge.From])
+		}
+	}
+
+	var resources []GraphNode
+	for _, node := range g.Nodes {
+		if node.Kind == NodeResource {
+			resources = append(resources, node)
+		}
+	}
+
+	for i, node := range resources {
+		branch, indent := "├── ", "│   "
+		if i == len(resources)-1 {
+			branch, indent = "└── ", "    "
+		}
+		b.WriteString(fmt.Sprintf("%s%s %s [%s]\n", branch, node.ResourceType, node.Label, node.RiskLevel))
+
+		users := sharedWith[node.ID]
+		for j, user := range users {
+			if j == len(users)-1 {
+				b.WriteString(fmt.Sprintf("%s└── %s\n", indent, user))
+			} else {
+				b.WriteString(fmt.Sprintf("%s├── %s\n", indent, user))
+			}
+		}
+	}
+
+	if len(resources) == 0 {
+		b.WriteString("└── (no resources)\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,268 @@
+// Package apitest provides an in-memory api.ClientInterface for tests
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// MockCall is one call made to a MockClient
+type MockCall struct {
+	Method string
+	Args   []any
+}
+
+// MockClient is an in-memory api.ClientInterface for tests. Lookups answer from
+// the fields below, keyed by resource ID or name, and return zero values for
+// anything not set. A method whose name is in Errors fails with that error.
+// Every call is recorded and can be read back with Calls.
+type MockClient struct {
+	Ctx context.Context
+
+	Workers                 []types.WorkerInfo
+	Bindings                map[string][]types.Binding     // By script name
+	Routes                  map[string][]types.WorkerRoute // By worker name
+	Names                   map[string]string              // KV, D1 and Hyperdrive names by ID
+	KeyCounts               map[string]int                 // KV keys by namespace ID
+	TableCounts             map[string]int                 // D1 tables by database ID
+	Locations               map[string]string              // R2 location hints by bucket name
+	StorageBytes            map[string]int64               // R2 bytes by bucket name
+	ObjectCounts            map[string]int                 // R2 objects by bucket name
+	Queues                  map[string]*types.QueueDetails
+	Tags                    map[string][]string // By resource ID
+	DurableObjectNamespaces []types.DurableObjectNamespace
+	KVValues                map[string]map[string][]byte // By namespace ID, then key
+	D1Rows                  map[string][]map[string]any  // Returned for every query, by database ID
+	Errors                  map[string]error             // By method name
+	Latency                 time.Duration                // Added to every call, to stand in for the network
+
+	mu    sync.Mutex
+	calls []MockCall
+}
+
+var _ api.ClientInterface = (*MockClient)(nil)
+
+// NewMockClient creates an empty MockClient
+func NewMockClient() *MockClient {
+	return &MockClient{Errors: map[string]error{}}
+}
+
+// Calls returns every call made so far, in order
+func (m *MockClient) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// CallCount returns how many times method was called
+func (m *MockClient) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, call := range m.calls {
+		if call.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// record notes a call and returns the error configured for the method, after
+// waiting out Latency
+func (m *MockClient) record(method string, args ...any) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, MockCall{Method: method, Args: args})
+	err := m.Errors[method]
+	m.mu.Unlock()
+
+	if m.Latency > 0 {
+		time.Sleep(m.Latency)
+	}
+	return err
+}
+
+func (m *MockClient) Context() context.Context {
+	if m.Ctx != nil {
+		return m.Ctx
+	}
+	return context.Background()
+}
+
+func (m *MockClient) ListWorkers() ([]types.WorkerInfo, error) {
+	if err := m.record("ListWorkers"); err != nil {
+		return nil, err
+	}
+	return append([]types.WorkerInfo(nil), m.Workers...), nil
+}
+
+func (m *MockClient) GetWorkerBindings(scriptName string) ([]types.Binding, error) {
+	if err := m.record("GetWorkerBindings", scriptName); err != nil {
+		return nil, err
+	}
+	return m.Bindings[scriptName], nil
+}
+
+// GetWorkerRoutes returns the configured routes even when it fails, as the
+// client does when some zones couldn't be listed
+func (m *MockClient) GetWorkerRoutes(workerName string) ([]types.WorkerRoute, error) {
+	err := m.record("GetWorkerRoutes", workerName)
+	return m.Routes[workerName], err
+}
+
+func (m *MockClient) DeleteWorker(name string) error {
+	return m.record("DeleteWorker", name)
+}
+
+func (m *MockClient) DeleteWorkerRoute(zoneID, routeID string) error {
+	return m.record("DeleteWorkerRoute", zoneID, routeID)
+}
+
+func (m *MockClient) DeleteWorkerSecret(scriptName, secretName string) error {
+	return m.record("DeleteWorkerSecret", scriptName, secretName)
+}
+
+func (m *MockClient) ClearWorkerCronTriggers(scriptName string) error {
+	return m.record("ClearWorkerCronTriggers", scriptName)
+}
+
+func (m *MockClient) WaitForWorkerDeletion(name string, interval, timeout time.Duration) error {
+	return m.record("WaitForWorkerDeletion", name, interval, timeout)
+}
+
+func (m *MockClient) GetKVNamespaceTitle(namespaceID string) (string, error) {
+	if err := m.record("GetKVNamespaceTitle", namespaceID); err != nil {
+		return "", err
+	}
+	return m.Names[namespaceID], nil
+}
+
+func (m *MockClient) GetKVNamespaceKeyCount(namespaceID string) (int, error) {
+	if err := m.record("GetKVNamespaceKeyCount", namespaceID); err != nil {
+		return 0, err
+	}
+	return m.KeyCounts[namespaceID], nil
+}
+
+func (m *MockClient) GetD1DatabaseName(databaseID string) (string, error) {
+	if err := m.record("GetD1DatabaseName", databaseID); err != nil {
+		return "", err
+	}
+	return m.Names[databaseID], nil
+}
+
+func (m *MockClient) GetD1DatabaseTableCount(databaseID string) (int, error) {
+	if err := m.record("GetD1DatabaseTableCount", databaseID); err != nil {
+		return 0, err
+	}
+	return m.TableCounts[databaseID], nil
+}
+
+func (m *MockClient) GetR2BucketLocation(bucketName, jurisdiction string) (string, error) {
+	if err := m.record("GetR2BucketLocation", bucketName, jurisdiction); err != nil {
+		return "", err
+	}
+	return m.Locations[bucketName], nil
+}
+
+func (m *MockClient) GetR2BucketStorageUsage(bucketName, jurisdiction string) (int64, int, error) {
+	if err := m.record("GetR2BucketStorageUsage", bucketName, jurisdiction); err != nil {
+		return 0, 0, err
+	}
+	return m.StorageBytes[bucketName], m.ObjectCounts[bucketName], nil
+}
+
+func (m *MockClient) GetHyperdriveConfigName(configID string) (string, error) {
+	if err := m.record("GetHyperdriveConfigName", configID); err != nil {
+		return "", err
+	}
+	return m.Names[configID], nil
+}
+
+// GetQueueDetails fails for a queue missing from Queues, like the real
+// lookup does
+func (m *MockClient) GetQueueDetails(queueName string) (*types.QueueDetails, error) {
+	if err := m.record("GetQueueDetails", queueName); err != nil {
+		return nil, err
+	}
+	queue, ok := m.Queues[queueName]
+	if !ok {
+		return nil, fmt.Errorf("queue %s not found", queueName)
+	}
+	return queue, nil
+}
+
+func (m *MockClient) GetResourceTags(resourceType types.BindingType, resourceID string) ([]string, error) {
+	if err := m.record("GetResourceTags", resourceType, resourceID); err != nil {
+		return nil, err
+	}
+	return m.Tags[resourceID], nil
+}
+
+func (m *MockClient) ListDurableObjectNamespaces() ([]types.DurableObjectNamespace, error) {
+	if err := m.record("ListDurableObjectNamespaces"); err != nil {
+		return nil, err
+	}
+	return m.DurableObjectNamespaces, nil
+}
+
+func (m *MockClient) ListKVKeys(namespaceID string) ([]string, error) {
+	if err := m.record("ListKVKeys", namespaceID); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for key := range m.KVValues[namespaceID] {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *MockClient) GetKVValue(namespaceID, key string) ([]byte, error) {
+	if err := m.record("GetKVValue", namespaceID, key); err != nil {
+		return nil, err
+	}
+	return m.KVValues[namespaceID][key], nil
+}
+
+func (m *MockClient) QueryD1Database(databaseID, sql string) ([]map[string]any, error) {
+	if err := m.record("QueryD1Database", databaseID, sql); err != nil {
+		return nil, err
+	}
+	return m.D1Rows[databaseID], nil
+}
+
+func (m *MockClient) DeleteKVNamespace(namespaceID string) error {
+	return m.record("DeleteKVNamespace", namespaceID)
+}
+
+func (m *MockClient) EmptyR2Bucket(bucketName, jurisdiction string) error {
+	return m.record("EmptyR2Bucket", bucketName, jurisdiction)
+}
+
+func (m *MockClient) DeleteR2Bucket(bucketName, jurisdiction string) error {
+	return m.record("DeleteR2Bucket", bucketName, jurisdiction)
+}
+
+func (m *MockClient) DeleteD1Database(databaseID string) error {
+	return m.record("DeleteD1Database", databaseID)
+}
+
+func (m *MockClient) DeleteQueue(queueName string) error {
+	return m.record("DeleteQueue", queueName)
+}
+
+func (m *MockClient) DeleteHyperdriveConfig(configID string) error {
+	return m.record("DeleteHyperdriveConfig", configID)
+}
+
+func (m *MockClient) DeleteVectorizeIndex(indexName string) error {
+	return m.record("DeleteVectorizeIndex", indexName)
+}
+
+func (m *MockClient) DeleteDurableObjectNamespace(namespaceID string) error {
+	return m.record("DeleteDurableObjectNamespace", namespaceID)
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// newTestClient returns a client whose SDK requests go to srv
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...ClientOption) *Client {
+	t.Helper()
+	opts = append(opts, func(o *clientOptions) { o.baseURL = srv.URL })
+	client, err := NewClient("token", "account", opts...)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+// Bindings are cached by the analyzer with an expiry, the client must always
+// read them fresh
+func TestGetWorkerBindingsNotCached(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"success":true,"errors":[],"messages":[],"result":{"bindings":[{"type":"kv_namespace","name":"CACHE","namespace_id":"ns%d"}]}}`, n)
+	}))
+	t.Cleanup(srv.Close)
+	client := newTestClient(t, srv)
+
+	for _, want := range []string{"ns1", "ns2"} {
+		bindings, err := client.GetWorkerBindings("app")
+		if err != nil {
+			t.Fatalf("GetWorkerBindings() error = %v", err)
+		}
+		if len(bindings) != 1 || bindings[0].NamespaceID != want {
+			t.Errorf("GetWorkerBindings() = %v, want namespace %s", bindings, want)
+		}
+	}
+}
+
+func TestDeleteR2BucketJurisdiction(t *testing.T) {
+	tests := []struct {
+		jurisdiction string
+		wantHeader   string
+	}{
+		{"", ""},
+		{"eu", "eu"},
+	}
+
+	for _, tt := range tests {
+		t.Run("jurisdiction "+tt.jurisdiction, func(t *testing.T) {
+			var header string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Get("cf-r2-jurisdiction")
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"success":true,"errors":[],"messages":[],"result":{}}`)
+			}))
+			t.Cleanup(srv.Close)
+
+			if err := newTestClient(t, srv).DeleteR2Bucket("uploads", tt.jurisdiction); err != nil {
+				t.Fatalf("DeleteR2Bucket() error = %v", err)
+			}
+			if header != tt.wantHeader {
+				t.Errorf("cf-r2-jurisdiction = %q, want %q", header, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestParseBindingKnownTypes(t *testing.T) {
+	tests := []struct {
+		rawType string
+		want    types.BindingType
+	}{
+		{"ai", "ai"},
+		{"json", "json"},
+		{"assets", "assets"},
+		{"wasm_module", "wasm_module"},
+		{"text_blob", "text_blob"},
+		{"data_blob", "data_blob"},
+		{"send_email", "send_email"},
+		{"inherit", "inherit"},
+		{"something_new", types.BindingTypeUnknown},
+	}
+
+	c := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.rawType, func(t *testing.T) {
+			binding := c.parseBinding(map[string]interface{}{"type": tt.rawType, "name": "BINDING"})
+			if binding == nil || binding.Type != tt.want {
+				t.Errorf("parseBinding(%q) = %+v, want type %q", tt.rawType, binding, tt.want)
+			}
+		})
+	}
+}
+
+// concurrentRequests is how many requests each pooling round sends at once
+const concurrentRequests = 50
+
+// sendConcurrently sends concurrentRequests GETs to srv at once and returns how
+// many of them reused an idle connection
+func sendConcurrently(tb testing.TB, client *http.Client, srv *httptest.Server) int {
+	tb.Helper()
+	var reused atomic.Int32
+	var wg sync.WaitGroup
+	for range concurrentRequests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			trace := &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) {
+					if info.Reused {
+						reused.Add(1)
+					}
+				},
+			}
+			req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, srv.URL, nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				tb.Errorf("request failed: %v", err)
+				return
+			}
+			// Connections only go back to the pool once the body is drained
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	return int(reused.Load())
+}
+
+// slowServer holds each request briefly, so concurrent requests overlap
+func slowServer(tb testing.TB) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPooledTransportReuse(t *testing.T) {
+	srv := slowServer(t)
+	transport := newPooledTransport()
+	t.Cleanup(transport.CloseIdleConnections)
+	client := &http.Client{Transport: transport}
+
+	// The first round opens the connections, the second should find them
+	// idle. The default transport keeps only 2 per host, so most would
+	// reconnect; allow a few to miss in case round one opened fewer.
+	sendConcurrently(t, client, srv)
+	if reused := sendConcurrently(t, client, srv); reused < concurrentRequests/2 {
+		t.Errorf("%d of %d requests reused a connection, want most", reused, concurrentRequests)
+	}
+}
+
+// BenchmarkPooledTransport sends rounds of concurrent requests; after the
+// first round every request should reuse a pooled connection
+func BenchmarkPooledTransport(b *testing.B) {
+	srv := slowServer(b)
+	transport := newPooledTransport()
+	b.Cleanup(transport.CloseIdleConnections)
+	client := &http.Client{Transport: transport}
+
+	rounds, reused := 0, 0
+	for b.Loop() {
+		reused += sendConcurrently(b, client, srv)
+		rounds++
+	}
+
+	// The first round opens the connections, most later requests should
+	// find one idle
+	if later := (rounds - 1) * concurrentRequests; reused < later/2 {
+		b.Errorf("%d of %d requests after the first round reused a connection, want most", reused, later)
+	}
+	b.ReportMetric(float64(reused)/float64(rounds*concurrentRequests), "reused/req")
+}
+
+func TestGetWorkerSubdomain(t *testing.T) {
+	var accountLookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/accounts/account/workers/subdomain":
+			accountLookups.Add(1)
+			fmt.Fprint(w, `{"success":true,"errors":[],"messages":[],"result":{"subdomain":"example"}}`)
+		case "/accounts/account/workers/scripts/public/subdomain":
+			fmt.Fprint(w, `{"success":true,"errors":[],"messages":[],"result":{"enabled":true}}`)
+		case "/accounts/account/workers/scripts/private/subdomain":
+			fmt.Fprint(w, `{"success":true,"errors":[],"messages":[],"result":{"enabled":false}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client := newTestClient(t, srv)
+
+	tests := []struct {
+		worker string
+		want   string
+	}{
+		{"public", "https://public.example.workers.dev"},
+		{"private", ""},
+		{"public", "https://public.example.workers.dev"},
+	}
+	for _, tt := range tests {
+		got, err := client.GetWorkerSubdomain(tt.worker)
+		if err != nil {
+			t.Fatalf("GetWorkerSubdomain(%q) error = %v", tt.worker, err)
+		}
+		if got != tt.want {
+			t.Errorf("GetWorkerSubdomain(%q) = %q, want %q", tt.worker, got, tt.want)
+		}
+	}
+
+	// The account's subdomain is the same for every worker
+	if got := accountLookups.Load(); got != 1 {
+		t.Errorf("account subdomain fetched %d times, want 1", got)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// ClientInterface is the part of Client the analyzer, the deleter and
+// snapshots use, so they can run against apitest.MockClient without network calls
+type ClientInterface interface {
+	Context() context.Context
+
+	// Workers
+	ListWorkers() ([]types.WorkerInfo, error)
+	GetWorkerBindings(scriptName string) ([]types.Binding, error)
+	GetWorkerRoutes(workerName string) ([]types.WorkerRoute, error)
+	DeleteWorker(name string) error
+	DeleteWorkerRoute(zoneID, routeID string) error
+	DeleteWorkerSecret(scriptName, secretName string) error
+	ClearWorkerCronTriggers(scriptName string) error
+	WaitForWorkerDeletion(name string, interval, timeout time.Duration) error
+
+	// Resource lookups
+	GetKVNamespaceTitle(namespaceID string) (string, error)
+	GetKVNamespaceKeyCount(namespaceID string) (int, error)
+	GetD1DatabaseName(databaseID string) (string, error)
+	GetD1DatabaseTableCount(databaseID string) (int, error)
+	GetR2BucketLocation(bucketName, jurisdiction string) (string, error)
+	GetR2BucketStorageUsage(bucketName, jurisdiction string) (int64, int, error)
+	GetHyperdriveConfigName(configID string) (string, error)
+	GetQueueDetails(queueName string) (*types.QueueDetails, error)
+	GetResourceTags(resourceType types.BindingType, resourceID string) ([]string, error)
+	ListDurableObjectNamespaces() ([]types.DurableObjectNamespace, error)
+
+	// Snapshots
+	ListKVKeys(namespaceID string) ([]string, error)
+	GetKVValue(namespaceID, key string) ([]byte, error)
+	QueryD1Database(databaseID, sql string) ([]map[string]any, error)
+
+	// Resource deletion
+	DeleteKVNamespace(namespaceID string) error
+	EmptyR2Bucket(bucketName, jurisdiction string) error
+	DeleteR2Bucket(bucketName, jurisdiction string) error
+	DeleteD1Database(databaseID string) error
+	DeleteQueue(queueName string) error
+	DeleteHyperdriveConfig(configID string) error
+	DeleteVectorizeIndex(indexName string) error
+	DeleteDurableObjectNamespace(namespaceID string) error
+}
+
+var _ ClientInterface = (*Client)(nil)
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// loggingTransport writes every API request and its response status to a
+// structured logger. Each retry of a rate-limited request is logged on its own.
+type loggingTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+	token  string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	attrs := []any{
+		slog.String("method", req.Method),
+		slog.String("url", t.redactURL(req.URL)),
+		slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+	}
+	if err != nil {
+		t.logger.Error("api request failed", append(attrs, slog.String("error", t.redact(err.Error())))...)
+		return resp, err
+	}
+
+	level := slog.LevelInfo
+	if resp.StatusCode >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+	t.logger.Log(req.Context(), level, "api request", append(attrs, slog.Int("status", resp.StatusCode))...)
+	return resp, nil
+}
+
+// redactURL returns the URL with the API token and any token-like query
+// parameters removed
+func (t *loggingTransport) redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	if clean.RawQuery != "" {
+		query := clean.Query()
+		for key := range query {
+			if strings.Contains(strings.ToLower(key), "token") {
+				query.Set(key, redacted)
+			}
+		}
+		clean.RawQuery = query.Encode()
+	}
+	return t.redact(clean.String())
+}
+
+// redact removes the API token from a string
+func (t *loggingTransport) redact(s string) string {
+	if t.token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, t.token, redacted)
+}
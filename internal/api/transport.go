@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	apperrors "github.com/mattietk/cf-purge-worker/pkg/errors"
+)
+
+const (
+	// DefaultRetryMax is how many times a rate-limited request is retried
+	DefaultRetryMax = 3
+	// DefaultRetryWaitMax caps how long to wait before a single retry
+	DefaultRetryWaitMax = 30 * time.Second
+)
+
+// rateLimitedTransport retries requests that the API rejects with HTTP 429,
+// waiting for the duration given in the Retry-After header. Once the retries
+// are used up the request fails with a RateLimitError.
+type rateLimitedTransport struct {
+	base     http.RoundTripper
+	retryMax int
+	waitMax  time.Duration
+}
+
+func newRateLimitedTransport(base http.RoundTripper, retryMax int, waitMax time.Duration) *rateLimitedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitedTransport{
+		base:     base,
+		retryMax: retryMax,
+		waitMax:  waitMax,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		// Request bodies are consumed by each attempt, so rewind them for retries
+		if attempt > 0 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		// A request body that can't be replayed can't be retried
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait := t.retryAfter(resp, attempt)
+		resp.Body.Close()
+		if attempt >= t.retryMax {
+			return nil, &apperrors.RateLimitError{RetryAfter: wait}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter returns how long to wait before retrying a rate-limited response.
+// Falls back to exponential backoff when the header is missing or invalid.
+func (t *rateLimitedTransport) retryAfter(resp *http.Response, attempt int) time.Duration {
+	wait := time.Duration(1<<attempt) * time.Second
+
+	if header := resp.Header.Get("Retry-After"); header != "" {
+		if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(header); err == nil {
+			wait = time.Until(date)
+		}
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > t.waitMax {
+		wait = t.waitMax
+	}
+	return wait
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "github.com/mattietk/cf-purge-worker/pkg/errors"
+)
+
+// rateLimitedServer answers the first limited requests with 429 and a
+// Retry-After header, then succeeds with an empty API response
+func rateLimitedServer(t *testing.T, limited int32, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= limited {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"errors":[],"messages":[],"result":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestRateLimitedTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		limited    int32
+		retryMax   int
+		wantHits   int32
+		wantStatus int
+		wantErr    bool
+	}{
+		{"no rate limit", 0, 3, 1, http.StatusOK, false},
+		{"retried until success", 2, 3, 3, http.StatusOK, false},
+		{"retries used up", 10, 2, 3, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, hits := rateLimitedServer(t, tt.limited, "0")
+			client := &http.Client{Transport: newRateLimitedTransport(nil, tt.retryMax, time.Second)}
+
+			resp, err := client.Get(srv.URL)
+			if tt.wantErr {
+				var rateErr *apperrors.RateLimitError
+				if !errors.As(err, &rateErr) {
+					t.Fatalf("Get() error = %v, want RateLimitError", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("server hit %d times, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestRateLimitedTransportWaitMax(t *testing.T) {
+	srv, hits := rateLimitedServer(t, 1, "60")
+	client := &http.Client{Transport: newRateLimitedTransport(nil, 1, 10*time.Millisecond)}
+
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retry waited %s, want it capped near 10ms", elapsed)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server hit %d times, want 2", got)
+	}
+}
+
+// The SDK has its own retry loop around the transport; NewClient must turn it
+// off so a rate-limited call is tried retryMax+1 times, not that many per
+// SDK attempt
+func TestNewClientRetriesOnce(t *testing.T) {
+	srv, hits := rateLimitedServer(t, 100, "0")
+
+	client := newTestClient(t, srv, WithRetry(2, time.Second))
+
+	if _, err := client.ListAccounts(); err == nil {
+		t.Fatal("ListAccounts() succeeded, want a rate limit error")
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("server hit %d times, want 3", got)
+	}
+}
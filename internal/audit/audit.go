@@ -0,0 +1,62 @@
+// Package audit appends a JSON record of each deletion plan and result to a
+// file so repeated runs build up an audit trail
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// Record is one line of the audit file
+type Record struct {
+	Timestamp time.Time             `json:"timestamp"`
+	AccountID string                `json:"account_id"`
+	User      string                `json:"user"`
+	Worker    string                `json:"worker"`
+	DryRun    bool                  `json:"dry_run"`
+	Plan      *types.DeletionPlan   `json:"plan,omitempty"`
+	Result    *types.DeletionResult `json:"result,omitempty"`
+}
+
+// Append writes record to the end of the file at path as a single JSON line,
+// creating the file if needed
+func Append(path string, record Record) error {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+	if record.User == "" {
+		record.User = CurrentUser()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// CurrentUser returns the name of the user running the tool
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
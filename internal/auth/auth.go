@@ -9,6 +9,8 @@ import (
 	"strings"
 	"syscall"
 
+	apperrors "github.com/mattietk/cf-purge-worker/pkg/errors"
+	"github.com/zalando/go-keyring"
 	"golang.org/x/term"
 )
 
@@ -17,23 +19,95 @@ const (
 	credsFile  = "credentials"
 )
 
+// Keychain entry the token is stored under. A profile's token is stored as
+// the user api-token:<profile>.
+const (
+	keychainService = "cf-purge-worker"
+	keychainUser    = "api-token"
+)
+
+// TokenURL is where API tokens are created
+const TokenURL = "https://dash.cloudflare.com/profile/api-tokens"
+
+// RequiredPermissions are the token permissions the tool needs
+var RequiredPermissions = []string{
+	"Workers Scripts: Edit",
+	"Workers KV Storage: Edit",
+	"Workers R2 Storage: Edit",
+	"Workers D1: Edit",
+	"Account Settings: Read",
+}
+
 // Manager handles API key storage and retrieval
 type Manager struct {
-	configPath string
+	configPath  string
+	useKeychain bool
+	// profile selects a named credential, empty for the default one
+	profile string
+}
+
+// Option configures a Manager
+type Option func(*Manager)
+
+// WithoutKeychain stores and reads the token only from the credentials file
+func WithoutKeychain() Option {
+	return func(m *Manager) {
+		m.useKeychain = false
+	}
+}
+
+// WithProfile stores and reads the token of a named profile, kept in
+// credentials.<profile> or its own keychain entry. Reads fall back to the
+// default token when the profile has none stored.
+func WithProfile(profile string) Option {
+	return func(m *Manager) {
+		m.profile = profile
+	}
 }
 
-// NewManager creates a new auth manager
-func NewManager() *Manager {
+// NewManager creates a new auth manager. The token is kept in the OS
+// keychain where one is available, falling back to the credentials file.
+func NewManager(opts ...Option) *Manager {
 	homeDir, _ := os.UserHomeDir()
-	return &Manager{
-		configPath: filepath.Join(homeDir, configDir),
+	m := &Manager{
+		configPath:  filepath.Join(homeDir, configDir),
+		useKeychain: true,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
 // GetAPIKey retrieves the stored API key or prompts for it
 func (m *Manager) GetAPIKey() (string, error) {
-	// First check environment variable (for CI/CD)
-	if key := os.Getenv("CLOUDFLARE_API_TOKEN"); key != "" {
+	if key, err := m.LookupAPIKey(); err == nil {
+		return key, nil
+	}
+
+	// No stored key, prompt user
+	return m.PromptForAPIKey()
+}
+
+// tokenEnvVars are checked in order for an API token. CF_API_TOKEN is the
+// name wrangler uses.
+var tokenEnvVars = []string{"CLOUDFLARE_API_TOKEN", "CF_API_TOKEN"}
+
+// EnvAPIKey returns the API token set in the environment, if any
+func EnvAPIKey() string {
+	for _, name := range tokenEnvVars {
+		if key := os.Getenv(name); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// LookupAPIKey returns the API key from the environment or stored
+// credentials without prompting
+func (m *Manager) LookupAPIKey() (string, error) {
+	// First check environment variables (for CI/CD)
+	if key := EnvAPIKey(); key != "" {
 		return key, nil
 	}
 
@@ -43,20 +117,76 @@ func (m *Manager) GetAPIKey() (string, error) {
 		return key, nil
 	}
 
-	// No stored key, prompt user
-	return m.PromptForAPIKey()
+	// Never fall back to the default token, it may belong to another account
+	if m.profile != "" {
+		return "", &apperrors.AuthError{Message: fmt.Sprintf("no API token found for profile %q, run `cf-purge-worker auth login --profile %s`", m.profile, m.profile)}
+	}
+
+	return "", &apperrors.AuthError{Message: "no API token found, run `cf-purge-worker auth login`"}
+}
+
+// CredentialsPath returns the path of the stored credentials file
+func (m *Manager) CredentialsPath() string {
+	if m.profile != "" {
+		// Keep the file in the config directory whatever the profile is called
+		name := strings.NewReplacer("/", "_", `\`, "_").Replace(m.profile)
+		return filepath.Join(m.configPath, credsFile+"."+name)
+	}
+	return filepath.Join(m.configPath, credsFile)
+}
+
+// Profile returns the name of the profile whose token is managed, empty for
+// the default one
+func (m *Manager) Profile() string {
+	return m.profile
+}
+
+// keychainAccount returns the keychain user the token is stored under
+func (m *Manager) keychainAccount() string {
+	if m.profile != "" {
+		return keychainUser + ":" + m.profile
+	}
+	return keychainUser
+}
+
+// HasStoredKey reports whether a token is stored in the keychain or the
+// credentials file
+func (m *Manager) HasStoredKey() bool {
+	if m.hasKeychainKey() {
+		return true
+	}
+	_, err := os.Stat(m.CredentialsPath())
+	return err == nil
+}
+
+// StorageLocations describes where a stored token may be kept
+func (m *Manager) StorageLocations() []string {
+	var locations []string
+	if m.hasKeychainKey() {
+		locations = append(locations, fmt.Sprintf("OS keychain (%s, %s)", keychainService, m.keychainAccount()))
+	}
+	if _, err := os.Stat(m.CredentialsPath()); err == nil {
+		locations = append(locations, m.CredentialsPath())
+	}
+	return locations
+}
+
+func (m *Manager) hasKeychainKey() bool {
+	if !m.useKeychain {
+		return false
+	}
+	key, err := keyring.Get(keychainService, m.keychainAccount())
+	return err == nil && key != ""
 }
 
 // PromptForAPIKey prompts the user to enter their API key
 func (m *Manager) PromptForAPIKey() (string, error) {
 	fmt.Println("\n🔑 Cloudflare API Token required")
-	fmt.Println("Create a token at: https://dash.cloudflare.com/profile/api-tokens")
+	fmt.Println("Create a token at: " + TokenURL)
 	fmt.Println("\nRequired permissions:")
-	fmt.Println("  • Workers Scripts: Edit")
-	fmt.Println("  • Workers KV Storage: Edit")
-	fmt.Println("  • Workers R2 Storage: Edit")
-	fmt.Println("  • Workers D1: Edit")
-	fmt.Println("  • Account Settings: Read")
+	for _, permission := range RequiredPermissions {
+		fmt.Println("  • " + permission)
+	}
 	fmt.Print("\nEnter your API token: ")
 
 	// Read password without echoing
@@ -68,7 +198,7 @@ func (m *Manager) PromptForAPIKey() (string, error) {
 
 	token := strings.TrimSpace(string(byteToken))
 	if token == "" {
-		return "", errors.New("token cannot be empty")
+		return "", &apperrors.AuthError{Message: "token cannot be empty"}
 	}
 
 	// Ask if they want to save it
@@ -88,15 +218,29 @@ func (m *Manager) PromptForAPIKey() (string, error) {
 	return token, nil
 }
 
-// SaveAPIKey saves the API key to disk
+// SaveAPIKey saves the API key to the keychain, or to disk when no keychain
+// is available
 func (m *Manager) SaveAPIKey(key string) error {
+	if m.useKeychain {
+		if err := keyring.Set(keychainService, m.keychainAccount(), key); err == nil {
+			// Don't leave a plain text copy behind
+			_ = m.deleteKeyFile()
+			return nil
+		}
+	}
+
+	return m.saveKeyFile(key)
+}
+
+// saveKeyFile writes the API key to the credentials file
+func (m *Manager) saveKeyFile(key string) error {
 	// Create config directory if it doesn't exist
 	if err := os.MkdirAll(m.configPath, 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	// Write key to file with restricted permissions
-	keyPath := filepath.Join(m.configPath, credsFile)
+	keyPath := m.CredentialsPath()
 	if err := os.WriteFile(keyPath, []byte(key), 0600); err != nil {
 		return fmt.Errorf("failed to write credentials: %w", err)
 	}
@@ -104,9 +248,20 @@ func (m *Manager) SaveAPIKey(key string) error {
 	return nil
 }
 
-// readStoredKey reads the API key from disk
+// readStoredKey reads the API key from the keychain or disk
 func (m *Manager) readStoredKey() (string, error) {
-	keyPath := filepath.Join(m.configPath, credsFile)
+	if m.useKeychain {
+		if key, err := keyring.Get(keychainService, m.keychainAccount()); err == nil && key != "" {
+			return key, nil
+		}
+	}
+
+	return m.readKeyFile()
+}
+
+// readKeyFile reads the API key from the credentials file
+func (m *Manager) readKeyFile() (string, error) {
+	keyPath := m.CredentialsPath()
 	data, err := os.ReadFile(keyPath)
 	if err != nil {
 		return "", err
@@ -115,9 +270,20 @@ func (m *Manager) readStoredKey() (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
-// DeleteStoredKey removes the stored API key
+// DeleteStoredKey removes the stored API key from the keychain and disk
 func (m *Manager) DeleteStoredKey() error {
-	keyPath := filepath.Join(m.configPath, credsFile)
+	if m.useKeychain {
+		if err := keyring.Delete(keychainService, m.keychainAccount()); err != nil && !errors.Is(err, keyring.ErrNotFound) && m.hasKeychainKey() {
+			return fmt.Errorf("failed to delete keychain entry: %w", err)
+		}
+	}
+
+	return m.deleteKeyFile()
+}
+
+// deleteKeyFile removes the credentials file
+func (m *Manager) deleteKeyFile() error {
+	keyPath := m.CredentialsPath()
 	if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete credentials: %w", err)
 	}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	apperrors "github.com/mattietk/cf-purge-worker/pkg/errors"
+)
+
+func TestLookupAPIKeyProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range tokenEnvVars {
+		t.Setenv(name, "")
+	}
+
+	if err := NewManager(WithoutKeychain()).SaveAPIKey("default-token"); err != nil {
+		t.Fatalf("SaveAPIKey() error = %v", err)
+	}
+
+	// The default token may belong to another account, a profile never uses it
+	_, err := NewManager(WithoutKeychain(), WithProfile("production")).LookupAPIKey()
+	var authErr *apperrors.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("LookupAPIKey() error = %v, want AuthError", err)
+	}
+
+	if err := NewManager(WithoutKeychain(), WithProfile("production")).SaveAPIKey("production-token"); err != nil {
+		t.Fatalf("SaveAPIKey() error = %v", err)
+	}
+	key, err := NewManager(WithoutKeychain(), WithProfile("production")).LookupAPIKey()
+	if err != nil || key != "production-token" {
+		t.Errorf("LookupAPIKey() = %q, %v, want production-token", key, err)
+	}
+}
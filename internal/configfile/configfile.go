@@ -0,0 +1,209 @@
+package configfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+const (
+	configDir  = ".config/cf-purge-worker"
+	configFile = "config.toml"
+)
+
+// Settings are the configurable options that can be set in the config file.
+// Pointer fields distinguish "not set" from zero values.
+type Settings struct {
+	APIToken            *string `toml:"api_token"`
+	AccountID           *string `toml:"account_id"`
+	DryRun              *bool   `toml:"dry_run"`
+	Force               *bool   `toml:"force"`
+	ExclusiveOnly       *bool   `toml:"exclusive_only"`
+	AutoYes             *bool   `toml:"yes"`
+	Verbose             *bool   `toml:"verbose"`
+	Quiet               *bool   `toml:"quiet"`
+	JSONOutput          *bool   `toml:"json"`
+	Summary             *bool   `toml:"summary"`
+	SkipDependencyCheck *bool   `toml:"skip_dependency_check"`
+	NoEnrichment        *bool   `toml:"no_enrichment"`
+	AnalysisConcurrency *int    `toml:"analysis_concurrency"`
+	UpdateCheck         *bool   `toml:"update_check"`
+	// Protect lists workers that must never be deleted. Profiles add to the
+	// top-level list rather than replacing it.
+	Protect []string `toml:"protect"`
+}
+
+// File is the on-disk config file. Top-level settings apply to every run,
+// and a named profile's settings are layered on top when selected.
+type File struct {
+	Settings
+	// DefaultProfile is used when no profile is given on the command line
+	DefaultProfile string              `toml:"default_profile"`
+	Profiles       map[string]Settings `toml:"profiles"`
+}
+
+// Profile describes a named profile for listing
+type Profile struct {
+	Name        string `json:"name"`
+	AccountID   string `json:"account_id,omitempty"`
+	HasAPIToken bool   `json:"has_api_token"`
+	Default     bool   `json:"default"`
+}
+
+// DefaultPath returns the default config file location
+func DefaultPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, configDir, configFile)
+}
+
+// Load reads the config file at path. A missing file is only an error when
+// the path was given explicitly.
+func Load(path string, explicit bool) (*File, error) {
+	var file File
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Resolve returns the effective settings for a profile. An empty profile
+// selects default_profile, or only the top-level settings without one.
+func (f *File) Resolve(profile string) (Settings, error) {
+	settings := f.Settings
+	if profile == "" {
+		profile = f.DefaultProfile
+	}
+	if profile == "" {
+		return settings, nil
+	}
+
+	p, ok := f.Profiles[profile]
+	if !ok {
+		return Settings{}, fmt.Errorf("profile not found in config file: %s", profile)
+	}
+
+	return settings.merge(p), nil
+}
+
+// ListProfiles returns the file's profiles sorted by name, with the account
+// each one resolves to
+func (f *File) ListProfiles() []Profile {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]Profile, 0, len(names))
+	for _, name := range names {
+		settings := f.Settings.merge(f.Profiles[name])
+		profile := Profile{
+			Name:        name,
+			HasAPIToken: settings.APIToken != nil && *settings.APIToken != "",
+			Default:     name == f.DefaultProfile,
+		}
+		if settings.AccountID != nil {
+			profile.AccountID = *settings.AccountID
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+// merge returns s with every field that is set in override replaced
+func (s Settings) merge(override Settings) Settings {
+	if override.APIToken != nil {
+		s.APIToken = override.APIToken
+	}
+	if override.AccountID != nil {
+		s.AccountID = override.AccountID
+	}
+	if override.DryRun != nil {
+		s.DryRun = override.DryRun
+	}
+	if override.Force != nil {
+		s.Force = override.Force
+	}
+	if override.ExclusiveOnly != nil {
+		s.ExclusiveOnly = override.ExclusiveOnly
+	}
+	if override.AutoYes != nil {
+		s.AutoYes = override.AutoYes
+	}
+	if override.Verbose != nil {
+		s.Verbose = override.Verbose
+	}
+	if override.Quiet != nil {
+		s.Quiet = override.Quiet
+	}
+	if override.JSONOutput != nil {
+		s.JSONOutput = override.JSONOutput
+	}
+	if override.Summary != nil {
+		s.Summary = override.Summary
+	}
+	if override.SkipDependencyCheck != nil {
+		s.SkipDependencyCheck = override.SkipDependencyCheck
+	}
+	if override.NoEnrichment != nil {
+		s.NoEnrichment = override.NoEnrichment
+	}
+	if override.AnalysisConcurrency != nil {
+		s.AnalysisConcurrency = override.AnalysisConcurrency
+	}
+	if override.UpdateCheck != nil {
+		s.UpdateCheck = override.UpdateCheck
+	}
+	s.Protect = append(append([]string{}, s.Protect...), override.Protect...)
+	return s
+}
+
+// Apply copies settings into cfg. changed reports whether a flag was set on
+// the command line; those values take precedence over the file.
+func (s Settings) Apply(cfg *types.Config, changed func(flag string) bool) {
+	setString := func(flag string, dst *string, src *string) {
+		if src != nil && !changed(flag) {
+			*dst = *src
+		}
+	}
+	setBool := func(flag string, dst *bool, src *bool) {
+		if src != nil && !changed(flag) {
+			*dst = *src
+		}
+	}
+	setInt := func(flag string, dst *int, src *int) {
+		if src != nil && !changed(flag) {
+			*dst = *src
+		}
+	}
+
+	setString("api-token", &cfg.APIKey, s.APIToken)
+	setString("account-id", &cfg.AccountID, s.AccountID)
+	setBool("dry-run", &cfg.DryRun, s.DryRun)
+	setBool("force", &cfg.Force, s.Force)
+	setBool("exclusive-only", &cfg.ExclusiveOnly, s.ExclusiveOnly)
+	setBool("yes", &cfg.AutoYes, s.AutoYes)
+	setBool("verbose", &cfg.Verbose, s.Verbose)
+	setBool("quiet", &cfg.Quiet, s.Quiet)
+	setBool("json", &cfg.JSONOutput, s.JSONOutput)
+	setBool("summary", &cfg.Summary, s.Summary)
+	setBool("skip-dependency-check", &cfg.SkipDependencyCheck, s.SkipDependencyCheck)
+	setBool("no-enrichment", &cfg.NoEnrichment, s.NoEnrichment)
+	setInt("analysis-concurrency", &cfg.AnalysisConcurrency, s.AnalysisConcurrency)
+	// update_check is the positive form of --no-update-check
+	if s.UpdateCheck != nil && !changed("no-update-check") {
+		cfg.NoUpdateCheck = !*s.UpdateCheck
+	}
+
+	// Protection only ever adds up, --protect can't lift the file's list
+	cfg.ProtectedWorkers = append(cfg.ProtectedWorkers, s.Protect...)
+}
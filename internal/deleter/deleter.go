@@ -1,67 +1,275 @@
 package deleter
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mattietk/cf-purge-worker/internal/api"
+	"github.com/mattietk/cf-purge-worker/internal/eventlog"
+	"github.com/mattietk/cf-purge-worker/internal/manifest"
+	"github.com/mattietk/cf-purge-worker/internal/snapshot"
+	apperrors "github.com/mattietk/cf-purge-worker/pkg/errors"
 	"github.com/mattietk/cf-purge-worker/pkg/types"
+	"golang.org/x/sync/errgroup"
 )
 
+// ValidatorFunc checks a resource before it is deleted. A non-nil error
+// prevents the resource from being deleted.
+type ValidatorFunc func(resource types.ResourceUsage) error
+
+// ProgressCallback is called before and after each resource deletion. Before,
+// current is the number of resources already processed; after, it includes
+// the resource just processed.
+type ProgressCallback func(current, total int, resourceName string)
+
 // Deleter handles deletion operations
 type Deleter struct {
-	client *api.Client
-	dryRun bool
+	client     api.ClientInterface
+	dryRun     bool
+	validators []ValidatorFunc
+	since      time.Time
+	logger     *slog.Logger
+
+	// Resources deleted at once, the worker script always goes first
+	concurrency int
+
+	// Manifest of deleted resources for the undo command
+	manifestPath string
+	accountID    string
+
+	// Data snapshots taken before KV and D1 deletions
+	snapshotDir   string
+	snapshotForce bool
 }
 
 // NewDeleter creates a new deleter
-func NewDeleter(client *api.Client, dryRun bool) *Deleter {
+func NewDeleter(client api.ClientInterface, dryRun bool) *Deleter {
 	return &Deleter{
-		client: client,
-		dryRun: dryRun,
+		client:      client,
+		dryRun:      dryRun,
+		validators:  []ValidatorFunc{HasResourceID()},
+		logger:      slog.New(slog.DiscardHandler),
+		concurrency: 1,
+	}
+}
+
+// AddValidator registers a validator that runs before each resource deletion
+func (d *Deleter) AddValidator(v ValidatorFunc) {
+	d.validators = append(d.validators, v)
+}
+
+// SetLogger logs the outcome of every worker and resource operation
+func (d *Deleter) SetLogger(logger *slog.Logger) {
+	d.logger = logger
+}
+
+// SetManifest records every live deletion in the manifest file at path before
+// anything is deleted
+func (d *Deleter) SetManifest(path, accountID string) {
+	d.manifestPath = path
+	d.accountID = accountID
+}
+
+// SetSnapshot saves the data of every KV namespace and D1 database to dir
+// before it is deleted. A resource whose snapshot fails is kept, unless force
+// is set.
+func (d *Deleter) SetSnapshot(dir string, force bool) {
+	d.snapshotDir = dir
+	d.snapshotForce = force
+}
+
+// SetSince makes Execute skip workers modified after t. A zero time disables
+// the check.
+func (d *Deleter) SetSince(t time.Time) {
+	d.since = t
+}
+
+// SetConcurrency deletes up to n resources at once. The worker script is
+// always deleted first, on its own. Above 1, ExecuteWithRollback no longer
+// reports partial failures, as the order resources went in is unknown.
+func (d *Deleter) SetConcurrency(n int) {
+	if n > 0 {
+		d.concurrency = n
+	}
+}
+
+// HasResourceID rejects KV namespaces and R2 buckets without an identifier,
+// so a delete call is never issued for a blank ID
+func HasResourceID() ValidatorFunc {
+	return func(resource types.ResourceUsage) error {
+		switch resource.ResourceType {
+		case types.BindingTypeKV, types.BindingTypeR2:
+			if strings.TrimSpace(resource.ResourceID) == "" {
+				return fmt.Errorf("%s has no resource ID", resource.ResourceName)
+			}
+		}
+		return nil
+	}
+}
+
+// NotShared rejects resources used by other workers. The deleted worker is
+// always one of the users, so a second one means the resource is shared.
+func NotShared() ValidatorFunc {
+	return func(resource types.ResourceUsage) error {
+		if resource.RiskLevel == types.RiskLevelUnknown || len(resource.Users()) > 1 {
+			return fmt.Errorf("%s is shared with other workers", resource.ResourceName)
+		}
+		return nil
+	}
+}
+
+// NotInExcludeList rejects resources whose ID or name is in the exclude list
+func NotInExcludeList(exclude []string) ValidatorFunc {
+	return func(resource types.ResourceUsage) error {
+		for _, entry := range exclude {
+			if entry == resource.ResourceID || entry == resource.ResourceName {
+				return fmt.Errorf("%s is in the exclude list", resource.ResourceName)
+			}
+		}
+		return nil
+	}
+}
+
+// validate runs all registered validators against a resource
+func (d *Deleter) validate(resource types.ResourceUsage) error {
+	for _, v := range d.validators {
+		if err := v(resource); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
 	}
+	return nil
 }
 
 // Execute executes the deletion plan
-func (d *Deleter) Execute(plan *types.DeletionPlan) (*types.DeletionResult, error) {
+func (d *Deleter) Execute(plan *types.DeletionPlan, progressCallback ...ProgressCallback) (*types.DeletionResult, error) {
+	// Get callback if provided
+	callback := func(current, total int, resourceName string) {}
+	if len(progressCallback) > 0 && progressCallback[0] != nil {
+		callback = progressCallback[0]
+	}
+
 	result := &types.DeletionResult{
 		Success:          true,
 		WorkerDeleted:    false,
 		ResourcesDeleted: []string{},
 		ResourcesSkipped: []string{},
 		Errors:           []error{},
+		StartedAt:        time.Now(),
+	}
+	defer func() { result.CompletedAt = time.Now() }()
+
+	if err := plan.Validate(); err != nil {
+		result.Success = false
+		result.Errors = append(result.Errors, err)
+		return result, err
+	}
+
+	// Recently modified workers may still be in use, leave them alone
+	if !d.since.IsZero() && plan.Worker.ModifiedOn.After(d.since) {
+		result.SkippedReason = fmt.Sprintf("worker was modified on %s, after %s",
+			plan.Worker.ModifiedOn.Format("2006-01-02"), d.since.Format("2006-01-02"))
+		d.logger.Warn("worker skipped", "worker", plan.Worker.Name, "reason", result.SkippedReason)
+		return result, nil
+	}
+
+	// Record what is about to go before touching anything, so a failed
+	// write stops the deletion rather than losing the record
+	if d.manifestPath != "" && !d.dryRun {
+		if err := manifest.Add(d.manifestPath, d.accountID, manifest.EntryFromPlan(plan)); err != nil {
+			result.Success = false
+			result.Errors = append(result.Errors, err)
+			return result, err
+		}
 	}
 
 	if d.dryRun {
 		// In dry-run mode, just simulate
-		result.WorkerDeleted = true
+		result.WorkerDeleted = !plan.SkipWorkerDeletion
+		result.WorkerPreserved = plan.SkipWorkerDeletion
+		if plan.DeleteSecrets {
+			result.SecretsDeleted = plan.Worker.SecretNames()
+		}
+		if plan.DeleteRoutes {
+			for _, route := range plan.Routes {
+				result.RoutesDeleted = append(result.RoutesDeleted, route.Pattern)
+			}
+		}
+		if !plan.SkipWorkerDeletion {
+			for _, trigger := range plan.Worker.CronTriggers {
+				result.CronsCleared = append(result.CronsCleared, trigger.Cron)
+			}
+		}
 		for _, resource := range plan.ResourcesToDelete {
 			result.ResourcesDeleted = append(result.ResourcesDeleted, resource.ResourceName)
 		}
 		return result, nil
 	}
 
-	// Step 1: Delete the worker script
-	if err := d.client.DeleteWorker(plan.Worker.Name); err != nil {
-		result.Success = false
-		result.Errors = append(result.Errors, fmt.Errorf("failed to delete worker: %w", err))
-		return result, err
+	// Step 1: Remove routes first so they don't serve errors once the worker is gone
+	if plan.DeleteRoutes {
+		for _, route := range plan.Routes {
+			if err := d.client.DeleteWorkerRoute(route.ZoneID, route.ID); err != nil {
+				d.logger.Error("route deletion failed", "route", route.Pattern, "zone_id", route.ZoneID, "error", err.Error())
+				result.Errors = append(result.Errors, fmt.Errorf("route %s: %w", route.Pattern, err))
+				continue
+			}
+			d.logger.Info("route deleted", "route", route.Pattern, "zone_id", route.ZoneID)
+			result.RoutesDeleted = append(result.RoutesDeleted, route.Pattern)
+		}
 	}
-	result.WorkerDeleted = true
 
-	// Step 2: Delete resources
-	for _, resource := range plan.ResourcesToDelete {
-		// Skip shared resources if we're not supposed to delete them
-		if !plan.DeleteShared && resource.RiskLevel != types.RiskLevelSafe {
-			result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
-			continue
+	// Step 2: Delete the worker script, unless only its resources are wanted.
+	// A kept worker's secrets are deleted one by one when asked for.
+	if plan.SkipWorkerDeletion {
+		result.WorkerPreserved = true
+		if plan.DeleteSecrets {
+			for _, name := range plan.Worker.SecretNames() {
+				if err := d.client.DeleteWorkerSecret(plan.Worker.Name, name); err != nil {
+					d.logger.Error("secret deletion failed", "worker", plan.Worker.Name, "secret", name, "error", err.Error())
+					result.Errors = append(result.Errors, err)
+					continue
+				}
+				d.logger.Info("secret deleted", "worker", plan.Worker.Name, "secret", name)
+				result.SecretsDeleted = append(result.SecretsDeleted, name)
+			}
+		}
+	} else {
+		// Clear schedules first so none fire against a half deleted worker
+		if len(plan.Worker.CronTriggers) > 0 {
+			if err := d.client.ClearWorkerCronTriggers(plan.Worker.Name); err != nil {
+				d.logger.Error("cron trigger removal failed", "worker", plan.Worker.Name, "error", err.Error())
+				result.Errors = append(result.Errors, err)
+			} else {
+				d.logger.Info("cron triggers cleared", "worker", plan.Worker.Name, "count", len(plan.Worker.CronTriggers))
+				for _, trigger := range plan.Worker.CronTriggers {
+					result.CronsCleared = append(result.CronsCleared, trigger.Cron)
+				}
+			}
 		}
 
-		if err := d.deleteResource(resource); err != nil {
-			result.Errors = append(result.Errors, err)
-			result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
-			// Continue with other resources even if one fails
-		} else {
-			result.ResourcesDeleted = append(result.ResourcesDeleted, resource.ResourceName)
+		if err := d.client.DeleteWorker(plan.Worker.Name); err != nil {
+			d.logger.Error("worker deletion failed", "worker", plan.Worker.Name, "error", err.Error())
+			result.Success = false
+			result.Errors = append(result.Errors, fmt.Errorf("failed to delete worker: %w", err))
+			return result, err
+		}
+		d.logger.Info("worker deleted", "worker", plan.Worker.Name)
+		result.WorkerDeleted = true
+	}
+
+	// Step 3: Delete resources
+	if d.concurrency > 1 {
+		d.processResourcesConcurrently(plan, result, callback)
+	} else {
+		total := len(plan.ResourcesToDelete)
+		for i, resource := range plan.ResourcesToDelete {
+			callback(i, total, resource.ResourceName)
+			d.processResource(plan, resource, result)
+			callback(i+1, total, resource.ResourceName)
 		}
 	}
 
@@ -73,6 +281,238 @@ func (d *Deleter) Execute(plan *types.DeletionPlan) (*types.DeletionResult, erro
 	return result, nil
 }
 
+// processResourcesConcurrently deletes the plan's resources with up to
+// d.concurrency in flight. Each resource is recorded in a result of its own,
+// merged in plan order once all are done, so the result reads the same as a
+// sequential run. Callbacks are serialised.
+func (d *Deleter) processResourcesConcurrently(plan *types.DeletionPlan, result *types.DeletionResult, callback ProgressCallback) {
+	total := len(plan.ResourcesToDelete)
+	outcomes := make([]types.DeletionResult, total)
+
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	var g errgroup.Group
+	g.SetLimit(d.concurrency)
+	for i, resource := range plan.ResourcesToDelete {
+		g.Go(func() error {
+			mu.Lock()
+			callback(done, total, resource.ResourceName)
+			mu.Unlock()
+
+			d.processResource(plan, resource, &outcomes[i])
+
+			mu.Lock()
+			done++
+			callback(done, total, resource.ResourceName)
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	for _, outcome := range outcomes {
+		result.ResourcesDeleted = append(result.ResourcesDeleted, outcome.ResourcesDeleted...)
+		result.ResourcesSkipped = append(result.ResourcesSkipped, outcome.ResourcesSkipped...)
+		result.Notices = append(result.Notices, outcome.Notices...)
+		result.Snapshots = append(result.Snapshots, outcome.Snapshots...)
+		result.Errors = append(result.Errors, outcome.Errors...)
+	}
+}
+
+// propagationPollInterval is how often a deleted worker is checked while
+// waiting for the deletion to propagate
+const propagationPollInterval = time.Second
+
+// AwaitPropagation waits until the deleted worker is no longer served, up to
+// timeout. A worker still reachable afterwards is reported as a notice, the
+// deletion itself succeeded.
+func (d *Deleter) AwaitPropagation(result *types.DeletionResult, workerName string, timeout time.Duration) {
+	if d.dryRun || result == nil || !result.WorkerDeleted {
+		return
+	}
+
+	if err := d.client.WaitForWorkerDeletion(workerName, propagationPollInterval, timeout); err != nil {
+		d.logger.Warn("propagation wait failed", "worker", workerName, "error", err.Error())
+		result.Notices = append(result.Notices, fmt.Sprintf("%s may still be served: %v", workerName, err))
+		return
+	}
+	d.logger.Info("deletion propagated", "worker", workerName)
+}
+
+// ExecuteWithRollback executes the plan like Execute and, when a failure
+// leaves it half done, marks the result as a partial failure. A
+// PartialDeletionError listing everything already deleted is added, and
+// joined to the returned error, so the state is never hidden behind a
+// generic failure. Deleted resources aren't re-created yet.
+func (d *Deleter) ExecuteWithRollback(plan *types.DeletionPlan, progressCallback ...ProgressCallback) (*types.DeletionResult, error) {
+	result, err := d.Execute(plan, progressCallback...)
+	if result == nil || d.dryRun || (err == nil && len(result.Errors) == 0) {
+		return result, err
+	}
+	// Resources deleted concurrently finish in no fixed order, so there is no
+	// reliable point at which the deletion was left half done
+	if d.concurrency > 1 {
+		return result, err
+	}
+
+	var deleted []string
+	for _, route := range result.RoutesDeleted {
+		deleted = append(deleted, "route "+route)
+	}
+	for _, secret := range result.SecretsDeleted {
+		deleted = append(deleted, "secret "+secret)
+	}
+	if len(result.CronsCleared) > 0 {
+		deleted = append(deleted, fmt.Sprintf("%d cron trigger(s)", len(result.CronsCleared)))
+	}
+	if result.WorkerDeleted {
+		deleted = append(deleted, "worker "+plan.Worker.Name)
+	}
+	deleted = append(deleted, result.ResourcesDeleted...)
+	if len(deleted) == 0 {
+		return result, err
+	}
+
+	partial := &apperrors.PartialDeletionError{Deleted: deleted}
+	for _, resErr := range result.Errors {
+		partial.Failed = append(partial.Failed, resErr.Error())
+	}
+
+	result.Success = false
+	result.PartialFailure = true
+	result.Errors = append(result.Errors, partial)
+	d.logger.Error("partial failure", "worker", plan.Worker.Name, "deleted", deleted)
+	if err != nil {
+		err = errors.Join(err, partial)
+	}
+	return result, err
+}
+
+// processResource deletes a single resource from the plan, or records why it
+// was left alone
+func (d *Deleter) processResource(plan *types.DeletionPlan, resource types.ResourceUsage, result *types.DeletionResult) {
+	// Skip shared resources if we're not supposed to delete them
+	if !plan.DeleteShared && resource.IsShared(plan.Worker.Name) {
+		d.logResource(plan.Worker.Name, resource, "skipped", nil)
+		result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
+		return
+	}
+
+	if err := d.validate(resource); err != nil {
+		d.logResource(plan.Worker.Name, resource, "rejected", err)
+		result.Errors = append(result.Errors, err)
+		result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
+		return
+	}
+
+	// Some resources have no delete API and must be removed by hand
+	if notice := manualDeletionNotice(resource); notice != "" {
+		d.logResource(plan.Worker.Name, resource, "manual", nil)
+		result.Notices = append(result.Notices, notice)
+		return
+	}
+
+	if err := d.snapshot(resource, result); err != nil {
+		if !d.snapshotForce {
+			d.logResource(plan.Worker.Name, resource, "snapshot failed", err)
+			result.Errors = append(result.Errors, err)
+			result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
+			return
+		}
+		d.logger.Warn("snapshot failed, deleting anyway", "resource_name", resource.ResourceName, "error", err.Error())
+		result.Notices = append(result.Notices, fmt.Sprintf("%s was deleted without a snapshot: %v", resource.ResourceName, err))
+	}
+
+	if err := d.emptyBucket(plan, resource, result); err != nil {
+		d.logResource(plan.Worker.Name, resource, "emptying failed", err)
+		result.Errors = append(result.Errors, err)
+		result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
+		return
+	}
+
+	if err := d.deleteResource(resource); err != nil {
+		d.logResource(plan.Worker.Name, resource, "failed", err)
+		result.Errors = append(result.Errors, err)
+		result.ResourcesSkipped = append(result.ResourcesSkipped, resource.ResourceName)
+		// Continue with other resources even if one fails
+		return
+	}
+	d.logResource(plan.Worker.Name, resource, "deleted", nil)
+	result.ResourcesDeleted = append(result.ResourcesDeleted, resource.ResourceName)
+}
+
+// snapshot saves the data of a KV namespace or D1 database when snapshots
+// are enabled, recording the file in the result
+func (d *Deleter) snapshot(resource types.ResourceUsage, result *types.DeletionResult) error {
+	if d.snapshotDir == "" {
+		return nil
+	}
+
+	var (
+		path string
+		err  error
+	)
+	switch resource.ResourceType {
+	case types.BindingTypeKV:
+		path, err = snapshot.WriteKVNamespace(d.client, d.snapshotDir, resource.ResourceID, resource.ResourceName)
+	case types.BindingTypeD1:
+		path, err = snapshot.WriteD1Database(d.client, d.snapshotDir, resource.ResourceID, resource.ResourceName)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot of %s failed: %w", resource.ResourceName, err)
+	}
+
+	d.logger.Info("snapshot written", "resource_name", resource.ResourceName, "path", path)
+	result.Snapshots = append(result.Snapshots, path)
+	return nil
+}
+
+// emptyBucket deletes the objects in an R2 bucket when the plan asks for it,
+// noting how much was removed in the result
+func (d *Deleter) emptyBucket(plan *types.DeletionPlan, resource types.ResourceUsage, result *types.DeletionResult) error {
+	if !plan.EmptyR2Buckets || resource.ResourceType != types.BindingTypeR2 {
+		return nil
+	}
+
+	if err := d.client.EmptyR2Bucket(resource.ResourceID, resource.Jurisdiction); err != nil {
+		return fmt.Errorf("emptying %s failed: %w", resource.ResourceName, err)
+	}
+
+	var size int64
+	if resource.StorageBytes != nil {
+		size = *resource.StorageBytes
+	}
+	d.logger.Info("bucket emptied", "resource_name", resource.ResourceName,
+		"objects", resource.ObjectCount, "bytes", size)
+	if resource.ObjectCount > 0 {
+		result.Notices = append(result.Notices, fmt.Sprintf("Emptied R2 bucket %s: %d object(s) deleted",
+			resource.ResourceName, resource.ObjectCount))
+	}
+	return nil
+}
+
+// logResource records the outcome of a single resource operation
+func (d *Deleter) logResource(worker string, resource types.ResourceUsage, outcome string, err error) {
+	eventlog.ResourceDeletion(d.logger, worker, resource, outcome, err)
+}
+
+// manualDeletionNotice returns a notice for resources that this tool cannot delete
+func manualDeletionNotice(resource types.ResourceUsage) string {
+	switch resource.ResourceType {
+	case types.BindingTypeMTLS:
+		return fmt.Sprintf("mTLS certificate %s: manual deletion required", resource.ResourceID)
+	case types.BindingTypeAnalyticsEngine:
+		// Datasets are created by the first write and have no delete API
+		return fmt.Sprintf("Analytics Engine dataset %s: no delete API, its data expires after 3 months", resource.ResourceID)
+	default:
+		return ""
+	}
+}
+
 // deleteResource deletes a specific resource based on its type
 func (d *Deleter) deleteResource(resource types.ResourceUsage) error {
 	switch resource.ResourceType {
@@ -80,24 +520,32 @@ func (d *Deleter) deleteResource(resource types.ResourceUsage) error {
 		return d.client.DeleteKVNamespace(resource.ResourceID)
 
 	case types.BindingTypeR2:
-		return d.client.DeleteR2Bucket(resource.ResourceID)
+		return d.client.DeleteR2Bucket(resource.ResourceID, resource.Jurisdiction)
 
 	case types.BindingTypeD1:
 		return d.client.DeleteD1Database(resource.ResourceID)
 
+	case types.BindingTypeHyperdrive:
+		return d.client.DeleteHyperdriveConfig(resource.ResourceID)
+
+	case types.BindingTypeVectorize:
+		return d.client.DeleteVectorizeIndex(resource.ResourceID)
+
 	case types.BindingTypeDurableObject:
-		// Durable Objects are defined in worker scripts, not separate resources
-		// No deletion needed
-		return nil
+		// The class lives in the worker script, but its namespace and stored
+		// data outlive it. Without a resolved namespace (classes defined by
+		// another worker never are) there is nothing of ours to delete.
+		if !resource.NamespaceResolved {
+			return nil
+		}
+		return d.client.DeleteDurableObjectNamespace(resource.ResourceID)
 
 	case types.BindingTypeService:
 		// Service bindings point to other workers, don't delete
 		return nil
 
 	case types.BindingTypeQueue:
-		// Queue deletion would require additional API calls
-		// Skip for now
-		return nil
+		return d.client.DeleteQueue(resource.ResourceID)
 
 	default:
 		return fmt.Errorf("unsupported resource type: %s", resource.ResourceType)
@@ -0,0 +1,255 @@
+package deleter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mattietk/cf-purge-worker/internal/api/apitest"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+func newPlan(resources ...types.ResourceUsage) *types.DeletionPlan {
+	return &types.DeletionPlan{
+		Worker:            types.WorkerInfo{Name: "app"},
+		ResourcesToDelete: resources,
+	}
+}
+
+var fullBucketBytes int64 = 4096
+
+var (
+	exclusiveKV = types.ResourceUsage{ResourceID: "ns1", ResourceType: types.BindingTypeKV, ResourceName: "exclusive", UsedBy: []string{"app"}, RiskLevel: types.RiskLevelSafe}
+	sharedKV    = types.ResourceUsage{ResourceID: "ns2", ResourceType: types.BindingTypeKV, ResourceName: "shared", UsedBy: []string{"app", "other"}, RiskLevel: types.RiskLevelCaution}
+	fullBucket  = types.ResourceUsage{ResourceID: "uploads", ResourceType: types.BindingTypeR2, ResourceName: "uploads", UsedBy: []string{"app"}, StorageBytes: &fullBucketBytes, RiskLevel: types.RiskLevelDanger}
+	exclusiveD1 = types.ResourceUsage{ResourceID: "db1", ResourceType: types.BindingTypeD1, ResourceName: "database", UsedBy: []string{"app"}, RiskLevel: types.RiskLevelSafe}
+)
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name         string
+		deleteShared bool
+		wantDeleted  []string
+		wantSkipped  []string
+	}{
+		{"keeps shared resources", false, []string{"exclusive"}, []string{"shared"}},
+		{"deletes shared resources", true, []string{"exclusive", "shared"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := apitest.NewMockClient()
+			plan := newPlan(exclusiveKV, sharedKV)
+			plan.DeleteShared = tt.deleteShared
+
+			result, err := NewDeleter(client, false).Execute(plan)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !result.Success || !result.WorkerDeleted {
+				t.Errorf("Success = %v, WorkerDeleted = %v, want both true", result.Success, result.WorkerDeleted)
+			}
+			if !slices.Equal(result.ResourcesDeleted, tt.wantDeleted) {
+				t.Errorf("ResourcesDeleted = %v, want %v", result.ResourcesDeleted, tt.wantDeleted)
+			}
+			if !slices.Equal(result.ResourcesSkipped, tt.wantSkipped) {
+				t.Errorf("ResourcesSkipped = %v, want %v", result.ResourcesSkipped, tt.wantSkipped)
+			}
+			if got := client.CallCount("DeleteKVNamespace"); got != len(tt.wantDeleted) {
+				t.Errorf("DeleteKVNamespace called %d times, want %d", got, len(tt.wantDeleted))
+			}
+		})
+	}
+}
+
+func TestExecuteFailedDelete(t *testing.T) {
+	client := apitest.NewMockClient()
+	client.Errors["DeleteKVNamespace"] = errors.New("namespace locked")
+
+	result, err := NewDeleter(client, false).Execute(newPlan(exclusiveKV, exclusiveD1))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Success {
+		t.Error("Success = true, want false")
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("got %d errors, want 1", len(result.Errors))
+	}
+	// A failed resource doesn't stop the ones after it
+	if !slices.Equal(result.ResourcesDeleted, []string{"database"}) {
+		t.Errorf("ResourcesDeleted = %v, want [database]", result.ResourcesDeleted)
+	}
+	if !slices.Equal(result.ResourcesSkipped, []string{"exclusive"}) {
+		t.Errorf("ResourcesSkipped = %v, want [exclusive]", result.ResourcesSkipped)
+	}
+}
+
+func TestExecuteFailedWorkerDelete(t *testing.T) {
+	client := apitest.NewMockClient()
+	client.Errors["DeleteWorker"] = errors.New("not found")
+
+	result, err := NewDeleter(client, false).Execute(newPlan(exclusiveKV))
+	if err == nil {
+		t.Fatal("Execute() error = nil, want an error")
+	}
+	if result.Success || result.WorkerDeleted {
+		t.Errorf("Success = %v, WorkerDeleted = %v, want both false", result.Success, result.WorkerDeleted)
+	}
+	if got := client.CallCount("DeleteKVNamespace"); got != 0 {
+		t.Errorf("DeleteKVNamespace called %d times, want 0", got)
+	}
+}
+
+func TestExecuteDryRun(t *testing.T) {
+	client := apitest.NewMockClient()
+
+	result, err := NewDeleter(client, true).Execute(newPlan(exclusiveKV))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !slices.Equal(result.ResourcesDeleted, []string{"exclusive"}) {
+		t.Errorf("ResourcesDeleted = %v, want [exclusive]", result.ResourcesDeleted)
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("dry run made %d API calls, want 0", len(calls))
+	}
+}
+
+func TestExecuteDurableObjectNamespace(t *testing.T) {
+	tests := []struct {
+		name       string
+		resource   types.ResourceUsage
+		wantDelete bool
+	}{
+		{
+			"resolved namespace",
+			types.ResourceUsage{ResourceID: "ns-counter", ResourceType: types.BindingTypeDurableObject, ResourceName: "Counter", NamespaceResolved: true},
+			true,
+		},
+		{
+			"class of another worker",
+			types.ResourceUsage{ResourceID: "Counter", ResourceType: types.BindingTypeDurableObject, ResourceName: "Counter"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := apitest.NewMockClient()
+
+			if _, err := NewDeleter(client, false).Execute(newPlan(tt.resource)); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := client.CallCount("DeleteDurableObjectNamespace") == 1; got != tt.wantDelete {
+				t.Errorf("namespace deleted = %v, want %v", got, tt.wantDelete)
+			}
+		})
+	}
+}
+
+// A bucket holding data is dangerous to delete but not shared, so plans that
+// keep shared resources still delete it
+func TestExecuteExclusiveFullBucket(t *testing.T) {
+	client := apitest.NewMockClient()
+	d := NewDeleter(client, false)
+	d.AddValidator(NotShared())
+
+	result, err := d.Execute(newPlan(fullBucket))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !slices.Equal(result.ResourcesDeleted, []string{"uploads"}) {
+		t.Errorf("ResourcesDeleted = %v, want [uploads]", result.ResourcesDeleted)
+	}
+	if got := client.CallCount("DeleteR2Bucket"); got != 1 {
+		t.Errorf("DeleteR2Bucket called %d times, want 1", got)
+	}
+}
+
+func TestExecuteValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator ValidatorFunc
+		resource  types.ResourceUsage
+	}{
+		{
+			"resource without an ID",
+			HasResourceID(),
+			types.ResourceUsage{ResourceType: types.BindingTypeKV, ResourceName: "blank", RiskLevel: types.RiskLevelSafe},
+		},
+		{
+			"shared resource",
+			NotShared(),
+			sharedKV,
+		},
+		{
+			"excluded resource",
+			NotInExcludeList([]string{"exclusive"}),
+			exclusiveKV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := apitest.NewMockClient()
+			d := NewDeleter(client, false)
+			d.AddValidator(tt.validator)
+			plan := newPlan(tt.resource)
+			plan.DeleteShared = true
+
+			result, err := d.Execute(plan)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := client.CallCount("DeleteKVNamespace"); got != 0 {
+				t.Errorf("DeleteKVNamespace called %d times, want 0", got)
+			}
+			if len(result.Errors) != 1 || result.Success {
+				t.Errorf("Errors = %v, Success = %v, want the validation error", result.Errors, result.Success)
+			}
+			if !slices.Equal(result.ResourcesSkipped, []string{tt.resource.ResourceName}) {
+				t.Errorf("ResourcesSkipped = %v, want [%s]", result.ResourcesSkipped, tt.resource.ResourceName)
+			}
+		})
+	}
+}
+
+func TestExecuteKeptWorkerSecrets(t *testing.T) {
+	tests := []struct {
+		name          string
+		deleteSecrets bool
+		wantDeleted   []string
+	}{
+		{"kept", false, nil},
+		{"deleted", true, []string{"API_KEY", "DB_PASSWORD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := apitest.NewMockClient()
+			plan := newPlan()
+			plan.Worker.Bindings = []types.Binding{
+				{Type: types.BindingTypeSecret, Name: "API_KEY"},
+				{Type: types.BindingTypeEnvVar, Name: "MODE"},
+				{Type: types.BindingTypeSecret, Name: "DB_PASSWORD"},
+			}
+			plan.SkipWorkerDeletion = true
+			plan.DeleteSecrets = tt.deleteSecrets
+
+			result, err := NewDeleter(client, false).Execute(plan)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !slices.Equal(result.SecretsDeleted, tt.wantDeleted) {
+				t.Errorf("SecretsDeleted = %v, want %v", result.SecretsDeleted, tt.wantDeleted)
+			}
+			if got := client.CallCount("DeleteWorkerSecret"); got != len(tt.wantDeleted) {
+				t.Errorf("DeleteWorkerSecret called %d times, want %d", got, len(tt.wantDeleted))
+			}
+			if got := client.CallCount("DeleteWorker"); got != 0 {
+				t.Errorf("DeleteWorker called %d times, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package eventlog logs a record for each step of a run through the shared
+// slog logger, marked with an event attribute. NewHandler writes only those
+// records, so --json-log can follow the interactive TUI with a
+// machine-readable log while --log-file keeps everything.
+package eventlog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// Event names, the value of the event attribute
+const (
+	EventAnalysisStarted   = "analysis_started"
+	EventAnalysisCompleted = "analysis_completed"
+	EventResourceDeletion  = "resource_deletion"
+	EventResult            = "result"
+)
+
+// eventKey is the attribute that marks a record as an event
+const eventKey = "event"
+
+// AnalysisStarted logs the start of a worker's dependency analysis
+func AnalysisStarted(logger *slog.Logger, worker string) {
+	logger.Info("analysis started", eventKey, EventAnalysisStarted, "worker", worker)
+}
+
+// AnalysisCompleted logs the number of resources an analysis found, or its error
+func AnalysisCompleted(logger *slog.Logger, worker string, resources []types.ResourceUsage, err error) {
+	if err != nil {
+		logger.Error("analysis completed", eventKey, EventAnalysisCompleted, "worker", worker, "error", err.Error())
+		return
+	}
+	logger.Info("analysis completed", eventKey, EventAnalysisCompleted, "worker", worker, "resources", len(resources))
+}
+
+// ResourceDeletion logs the outcome of one resource in a deletion: deleted,
+// skipped, failed...
+func ResourceDeletion(logger *slog.Logger, worker string, resource types.ResourceUsage, outcome string, err error) {
+	attrs := []any{
+		eventKey, EventResourceDeletion,
+		"worker", worker,
+		"resource_type", resource.ResourceType,
+		"resource_id", resource.ResourceID,
+		"resource_name", resource.ResourceName,
+		"outcome", outcome,
+	}
+	if err != nil {
+		logger.Error("resource operation", append(attrs, "error", err.Error())...)
+		return
+	}
+	logger.Info("resource operation", attrs...)
+}
+
+// Result logs the final result of a worker's deletion
+func Result(logger *slog.Logger, worker string, result *types.DeletionResult) {
+	logger.Info("deletion result", eventKey, EventResult, "worker", worker, "result", result)
+}
+
+// handler passes event records to a JSON handler and drops the rest
+type handler struct {
+	slog.Handler
+	// event is set once an event attribute was added with WithAttrs
+	event bool
+}
+
+// NewHandler returns a slog.Handler that writes the records logged by this
+// package to w, one JSON object per line, and drops every other record
+func NewHandler(w io.Writer) slog.Handler {
+	return &handler{Handler: slog.NewJSONHandler(w, nil)}
+}
+
+// Handle implements slog.Handler
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	event := h.event
+	r.Attrs(func(a slog.Attr) bool {
+		event = event || a.Key == eventKey
+		return !event
+	})
+	if !event {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{
+		Handler: h.Handler.WithAttrs(attrs),
+		event:   h.event || slices.ContainsFunc(attrs, func(a slog.Attr) bool { return a.Key == eventKey }),
+	}
+}
+
+// WithGroup implements slog.Handler
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{Handler: h.Handler.WithGroup(name), event: h.event}
+}
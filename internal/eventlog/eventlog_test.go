@@ -0,0 +1,53 @@
+package eventlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+func TestHandlerWritesOnlyEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf))
+
+	logger.Info("bucket emptied", "resource_name", "assets")
+	AnalysisStarted(logger, "api")
+	ResourceDeletion(logger, "api", types.ResourceUsage{ResourceType: types.BindingTypeKV, ResourceID: "kv-1"}, "failed", errors.New("boom"))
+	logger.Debug("worker deleted", "worker", "api")
+	Result(logger, "api", &types.DeletionResult{WorkerDeleted: true})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	wantEvents := []string{EventAnalysisStarted, EventResourceDeletion, EventResult}
+	if len(lines) != len(wantEvents) {
+		t.Fatalf("wrote %d lines, want %d:\n%s", len(lines), len(wantEvents), buf.String())
+	}
+	for i, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if record["event"] != wantEvents[i] {
+			t.Errorf("line %d event = %v, want %s", i, record["event"], wantEvents[i])
+		}
+		if record["worker"] != "api" {
+			t.Errorf("line %d worker = %v, want api", i, record["worker"])
+		}
+	}
+}
+
+func TestHandlerWithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf))
+
+	logger.With("worker", "api").Info("not an event")
+	logger.With("event", EventResult).Info("deletion result")
+
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("wrote %d lines, want 1:\n%s", got, buf.String())
+	}
+}
@@ -0,0 +1,101 @@
+// Package manifest records what a deletion is about to remove, so the
+// resources can be re-created later by the undo command
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// version is bumped whenever the file layout changes incompatibly
+const version = 1
+
+// Manifest is the on-disk record of one or more worker deletions
+type Manifest struct {
+	Version   int       `json:"version"`
+	AccountID string    `json:"account_id"`
+	Workers   []Entry   `json:"workers"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Entry is a single worker deletion
+type Entry struct {
+	Worker    string     `json:"worker"`
+	DeletedAt time.Time  `json:"deleted_at"`
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is a resource the deletion removes
+type Resource struct {
+	Type     types.BindingType `json:"type"`
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Location string            `json:"location,omitempty"`
+}
+
+// EntryFromPlan records the resources a plan will actually delete. Shared
+// resources are left out when the plan keeps them.
+func EntryFromPlan(plan *types.DeletionPlan) Entry {
+	entry := Entry{
+		Worker:    plan.Worker.Name,
+		DeletedAt: time.Now().UTC(),
+		Resources: []Resource{},
+	}
+	for _, resource := range plan.ResourcesToDelete {
+		if !plan.DeleteShared && resource.IsShared(plan.Worker.Name) {
+			continue
+		}
+		entry.Resources = append(entry.Resources, Resource{
+			Type:     resource.ResourceType,
+			ID:       resource.ResourceID,
+			Name:     resource.ResourceName,
+			Location: resource.Location,
+		})
+	}
+	return entry
+}
+
+// Add appends entry to the manifest at path, creating the file if needed.
+// Batch runs add one entry per worker to the same file.
+func Add(path, accountID string, entry Entry) error {
+	m, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		m = &Manifest{Version: version, AccountID: accountID}
+	} else if err != nil {
+		return err
+	}
+
+	m.Workers = append(m.Workers, entry)
+	m.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Read loads the manifest at path
+func Read(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.Version != version {
+		return nil, fmt.Errorf("unsupported manifest version %d in %s", m.Version, path)
+	}
+	return &m, nil
+}
@@ -0,0 +1,205 @@
+// Package snapshot saves the data held in KV namespaces and D1 databases to
+// local files, so it survives the resources being deleted
+package snapshot
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattietk/cf-purge-worker/internal/api"
+)
+
+// kvEntry is a single key in a KV snapshot. Values that aren't valid UTF-8
+// are base64 encoded.
+type kvEntry struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// WriteKVNamespace writes every key and value of a KV namespace to
+// <dir>/<title>-<id>.json and returns the file's path
+func WriteKVNamespace(client api.ClientInterface, dir, namespaceID, title string) (string, error) {
+	keys, err := client.ListKVKeys(namespaceID)
+	if err != nil {
+		return "", err
+	}
+
+	return writeFile(dir, fileName(title, namespaceID)+".json", func(w *bufio.Writer) error {
+		// Entries are written as they are read, one per line, to keep large
+		// namespaces out of memory
+		w.WriteString("[")
+		for i, key := range keys {
+			value, err := client.GetKVValue(namespaceID, key)
+			if err != nil {
+				return err
+			}
+
+			entry := kvEntry{Key: key, Value: string(value)}
+			if !utf8.Valid(value) {
+				entry.Value = base64.StdEncoding.EncodeToString(value)
+				entry.Encoding = "base64"
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to encode KV key %s: %w", key, err)
+			}
+
+			if i > 0 {
+				w.WriteString(",")
+			}
+			w.WriteString("\n  ")
+			w.Write(data)
+		}
+		w.WriteString("\n]\n")
+		return nil
+	})
+}
+
+// schemaQuery lists the schema objects of a D1 database, tables first, leaving
+// out SQLite's and Cloudflare's internal ones
+const schemaQuery = `SELECT type, name, sql FROM sqlite_master
+WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite!_%' ESCAPE '!' AND name NOT LIKE '!_cf!_%' ESCAPE '!'
+ORDER BY type != 'table', name`
+
+// WriteD1Database writes the schema and rows of a D1 database to
+// <dir>/<name>-<id>.sql as SQL statements and returns the file's path. Every table
+// is read with a single query, so very large tables may exceed the API's
+// response limits.
+func WriteD1Database(client api.ClientInterface, dir, databaseID, name string) (string, error) {
+	objects, err := client.QueryD1Database(databaseID, schemaQuery)
+	if err != nil {
+		return "", err
+	}
+
+	return writeFile(dir, fileName(name, databaseID)+".sql", func(w *bufio.Writer) error {
+		fmt.Fprintf(w, "-- Snapshot of D1 database %s (%s)\n", name, databaseID)
+
+		// Tables and their rows first, so indexes, views and triggers
+		// apply to existing tables
+		for _, object := range objects {
+			objectType, _ := object["type"].(string)
+			objectName, _ := object["name"].(string)
+			sql, _ := object["sql"].(string)
+
+			fmt.Fprintf(w, "\n%s;\n", sql)
+			if objectType != "table" {
+				continue
+			}
+			if err := writeTableRows(client, w, databaseID, objectName); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeTableRows writes an INSERT statement for every row of a table
+func writeTableRows(client api.ClientInterface, w *bufio.Writer, databaseID, table string) error {
+	// Rows come back as maps, the table info gives the column order
+	info, err := client.QueryD1Database(databaseID, fmt.Sprintf("PRAGMA table_info(%s)", quoteIdentifier(table)))
+	if err != nil {
+		return err
+	}
+	columns := make([]string, 0, len(info))
+	quoted := make([]string, 0, len(info))
+	for _, column := range info {
+		name, _ := column["name"].(string)
+		columns = append(columns, name)
+		quoted = append(quoted, quoteIdentifier(name))
+	}
+
+	rows, err := client.QueryD1Database(databaseID, "SELECT * FROM "+quoteIdentifier(table))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		values := make([]string, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, sqlLiteral(row[column]))
+		}
+		fmt.Fprintf(w, "INSERT INTO %s (%s) VALUES (%s);\n",
+			quoteIdentifier(table), strings.Join(quoted, ", "), strings.Join(values, ", "))
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a table or column name for SQLite
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlLiteral renders a value decoded from a D1 result as a SQL literal.
+// Integers beyond 2^53 lose precision in the JSON response.
+func sqlLiteral(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		data, _ := json.Marshal(v)
+		return "'" + strings.ReplaceAll(string(data), "'", "''") + "'"
+	}
+}
+
+// fileName turns a resource name and ID into a safe file name. Names aren't
+// unique across resources, so the ID is always part of it.
+func fileName(name, id string) string {
+	if name != "" && name != id {
+		name += "-" + id
+	} else {
+		name = id
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// writeFile creates dir/name and fills it with write. A file left incomplete
+// by a failure is removed.
+func writeFile(dir, name string, write func(w *bufio.Writer) error) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write snapshot %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,21 @@
+package snapshot
+
+import "testing"
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name, id string
+		want     string
+	}{
+		{"cache", "ns1", "cache-ns1"},
+		{"", "ns1", "ns1"},
+		{"ns1", "ns1", "ns1"},
+		{"my app/cache", "ns1", "my_app_cache-ns1"},
+	}
+
+	for _, tt := range tests {
+		if got := fileName(tt.name, tt.id); got != tt.want {
+			t.Errorf("fileName(%q, %q) = %q, want %q", tt.name, tt.id, got, tt.want)
+		}
+	}
+}
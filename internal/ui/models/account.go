@@ -0,0 +1,99 @@
+package models
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Default size of the account list until the terminal reports its size
+const (
+	accountListWidth  = 60
+	accountListHeight = 14
+)
+
+// AccountLister returns the accounts the API token can access
+type AccountLister func() ([]cloudflare.Account, error)
+
+// accountItem adapts a Cloudflare account to the list component
+type accountItem struct {
+	account cloudflare.Account
+}
+
+func (i accountItem) Title() string       { return i.account.Name }
+func (i accountItem) Description() string { return i.account.ID }
+func (i accountItem) FilterValue() string { return i.account.Name + " " + i.account.ID }
+
+// NewAccountSelectModel creates a model that lists the token's accounts and
+// lets the user pick one. The chosen ID is available from SelectedAccountID.
+func NewAccountSelectModel(listAccounts AccountLister) Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	return Model{
+		state:        stateSelectAccount,
+		spinner:      s,
+		message:      "Loading accounts...",
+		listAccounts: listAccounts,
+		workerCount:  -1,
+	}
+}
+
+// loadAccounts fetches the accounts to choose from
+func (m Model) loadAccounts() tea.Cmd {
+	return func() tea.Msg {
+		accounts, err := m.listAccounts()
+		if err != nil {
+			return accountsErrorMsg{err: err}
+		}
+		return accountsLoadedMsg{accounts: accounts}
+	}
+}
+
+// newAccountList builds the list component for the loaded accounts
+func newAccountList(accounts []cloudflare.Account) list.Model {
+	items := make([]list.Item, 0, len(accounts))
+	for _, account := range accounts {
+		items = append(items, accountItem{account: account})
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), accountListWidth, accountListHeight)
+	l.Title = "Select a Cloudflare account"
+	l.SetShowStatusBar(false)
+	return l
+}
+
+func (m Model) handleSelectAccountKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m.quit()
+	}
+
+	// Still loading
+	if m.accountList.Items() == nil {
+		return m, nil
+	}
+
+	filtering := m.accountList.FilterState() == list.Filtering
+	switch {
+	case msg.String() == "enter" && !filtering:
+		if item, ok := m.accountList.SelectedItem().(accountItem); ok {
+			m.SelectedAccountID = item.account.ID
+		}
+		return m.quit()
+	case msg.String() == "esc" && m.accountList.FilterState() == list.Unfiltered:
+		return m.quit()
+	}
+
+	var cmd tea.Cmd
+	m.accountList, cmd = m.accountList.Update(msg)
+	return m, cmd
+}
+
+type accountsLoadedMsg struct {
+	accounts []cloudflare.Account
+}
+
+type accountsErrorMsg struct {
+	err error
+}
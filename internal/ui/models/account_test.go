@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestAccountSelectWindowSize(t *testing.T) {
+	var m tea.Model = NewAccountSelectModel(nil)
+
+	// Terminals report their size before the accounts have loaded
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m, _ = m.Update(accountsLoadedMsg{accounts: []cloudflare.Account{{ID: "abc", Name: "Example"}}})
+
+	list := m.(Model).accountList
+	if list.Width() != 100 || list.Height() != 38 {
+		t.Errorf("account list is %dx%d, want 100x38", list.Width(), list.Height())
+	}
+
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	if list := m.(Model).accountList; list.Width() != 80 || list.Height() != 18 {
+		t.Errorf("account list is %dx%d after resize, want 80x18", list.Width(), list.Height())
+	}
+}
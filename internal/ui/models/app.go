@@ -1,21 +1,41 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/mattietk/cf-purge-worker/internal/analyzer"
 	"github.com/mattietk/cf-purge-worker/internal/deleter"
+	"github.com/mattietk/cf-purge-worker/internal/ui/styles"
 	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
 	"github.com/mattietk/cf-purge-worker/pkg/types"
 )
 
 type sessionState int
 
+// Progress bar width bounds; the bar follows the terminal width in between
+const (
+	progressBarPadding  = 6
+	progressBarMaxWidth = 60
+	// progressDetailIndent is the indent of the line below the progress bar
+	progressDetailIndent = 3
+)
+
+// planFooterLines is the space kept below the plan viewport for the prompt
+// and help bar
+const planFooterLines = 4
+
 const (
 	stateLoading sessionState = iota
 	stateConfirmDependencyCheck
@@ -23,9 +43,15 @@ const (
 	stateShowPlan
 	stateConfirmDeletion
 	stateConfirmShared
+	stateConfirmDanger
 	stateDeleting
+	stateWaitingPropagation
+	stateShowResult
 	stateComplete
 	stateError
+	stateSummary
+	stateSelectAccount
+	stateSelectResources
 )
 
 // progressTracker safely tracks analysis progress across goroutines
@@ -71,6 +97,41 @@ type Model struct {
 	analysisTotal    int
 	analysisWorker   string
 	progressTracker  *progressTracker
+	progressBar      progress.Model
+	// Deletion progress tracking
+	deletionProgress int
+	deletionTotal    int
+	deletionResource string
+	deletionTracker  *progressTracker
+	// Scrollable plan view, sized once the terminal size is known
+	planViewport viewport.Model
+	// Terminal size from the latest tea.WindowSizeMsg, 0 until it arrives
+	windowWidth  int
+	windowHeight int
+	// Resource under the cursor in the plan view, as a position in the
+	// plan's display order, and resources showing every sharing worker
+	selectedResource  int
+	expandedResources map[int]bool
+	// Worker count used for the pre-analysis estimate (-1 until known)
+	workerCount int
+	// done is set once the model has asked the program to quit
+	done bool
+	// Text typed in stateConfirmDanger
+	confirmInput string
+	// ctx ends the session with an error when it is cancelled or times out
+	ctx context.Context
+	// Account selection (stateSelectAccount)
+	listAccounts      AccountLister
+	accountList       list.Model
+	SelectedAccountID string
+	// Checkbox list for --interactive-select (stateSelectResources)
+	resourceList list.Model
+}
+
+// WithContext returns the model set to stop with an error when ctx is done
+func (m Model) WithContext(ctx context.Context) Model {
+	m.ctx = ctx
+	return m
 }
 
 // NewModel creates a new application model with a pre-computed plan
@@ -78,14 +139,17 @@ func NewModel(worker *types.WorkerInfo, plan *types.DeletionPlan, config *types.
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 
-	return Model{
-		state:   stateShowPlan,
-		worker:  worker,
-		plan:    plan,
-		config:  config,
-		deleter: d,
-		spinner: s,
+	m := Model{
+		state:       stateShowPlan,
+		worker:      worker,
+		plan:        plan,
+		config:      config,
+		deleter:     d,
+		spinner:     s,
+		progressBar: newProgressBar(),
 	}
+	m.syncPlanViewport()
+	return m
 }
 
 // NewModelWithAnalysis creates a new model that will run analysis interactively
@@ -108,19 +172,57 @@ func NewModelWithAnalysis(worker *types.WorkerInfo, a *analyzer.Analyzer, config
 		spinner:             s,
 		skipDependencyCheck: config.SkipDependencyCheck,
 		progressTracker:     &progressTracker{},
+		progressBar:         newProgressBar(),
+		workerCount:         -1,
 	}
 }
 
+// newProgressBar creates the bar shown while analyzing and deleting
+func newProgressBar() progress.Model {
+	return progress.New(
+		progress.WithSolidFill(string(styles.Orange)),
+		progress.WithWidth(progressBarMaxWidth),
+		progress.WithoutPercentage(),
+		progress.WithColorProfile(styles.ColorProfile()),
+	)
+}
+
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	if m.state == stateAnalyzing {
-		return tea.Batch(
-			m.spinner.Tick,
-			m.runAnalysis(),
-			m.pollProgress(),
-		)
+	cmds := []tea.Cmd{m.spinner.Tick}
+	if m.ctx != nil {
+		cmds = append(cmds, m.waitForContext())
+	}
+
+	switch m.state {
+	case stateAnalyzing:
+		cmds = append(cmds, m.runAnalysis(), m.pollProgress())
+	case stateConfirmDependencyCheck:
+		cmds = append(cmds, m.countWorkers())
+	case stateSelectAccount:
+		cmds = append(cmds, m.loadAccounts())
+	}
+	return tea.Batch(cmds...)
+}
+
+// waitForContext reports when the session's context is cancelled or times out
+func (m Model) waitForContext() tea.Cmd {
+	return func() tea.Msg {
+		<-m.ctx.Done()
+		return contextDoneMsg{err: m.ctx.Err()}
+	}
+}
+
+// countWorkers fetches the number of workers for the analysis estimate
+func (m Model) countWorkers() tea.Cmd {
+	return func() tea.Msg {
+		count, err := m.analyzer.CountWorkers()
+		if err != nil {
+			// The estimate is optional, fall back to the generic prompt
+			return workerCountMsg{count: -1}
+		}
+		return workerCountMsg{count: count}
 	}
-	return m.spinner.Tick
 }
 
 // pollProgress creates a command that polls for progress updates
@@ -130,6 +232,13 @@ func (m Model) pollProgress() tea.Cmd {
 	})
 }
 
+// pollDeletionProgress creates a command that polls for deletion progress
+func (m Model) pollDeletionProgress() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
+		return deletionProgressMsg{}
+	})
+}
+
 // runAnalysis runs the dependency analysis in the background
 func (m Model) runAnalysis() tea.Cmd {
 	return func() tea.Msg {
@@ -152,7 +261,10 @@ func (m Model) runAnalysis() tea.Cmd {
 		}
 
 		// Create deletion plan
-		plan := m.analyzer.CreateDeletionPlan(m.worker, resources, m.config.ExclusiveOnly)
+		plan := m.analyzer.CreateDeletionPlan(m.worker, resources, analyzer.PlanOptions{
+			ExclusiveOnly: m.config.ExclusiveOnly,
+			SkipEmpty:     m.config.SkipEmptyResources,
+		})
 		return analysisCompleteMsg{plan: plan}
 	}
 }
@@ -163,9 +275,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
 
+	case tea.WindowSizeMsg:
+		// Until the accounts load the list has no delegate to size, it is
+		// sized from the stored window size when they arrive
+		if m.state == stateSelectAccount && len(m.accountList.Items()) > 0 {
+			m.accountList.SetSize(msg.Width, msg.Height-2)
+		}
+		if m.state == stateSelectResources {
+			m.resourceList.SetSize(msg.Width, msg.Height-2)
+		}
+		m.progressBar.Width = min(max(msg.Width-progressBarPadding, 10), progressBarMaxWidth)
+		m.windowWidth, m.windowHeight = msg.Width, msg.Height
+		m.syncPlanViewport()
+
+	case progress.FrameMsg:
+		updated, cmd := m.progressBar.Update(msg)
+		m.progressBar = updated.(progress.Model)
+		return m, cmd
+
 	case spinner.TickMsg:
 		// Keep spinner running while in analyzing or deleting state
-		if m.state == stateAnalyzing || m.state == stateDeleting {
+		if m.state == stateAnalyzing || m.state == stateDeleting || m.state == stateWaitingPropagation || m.state == stateSelectAccount {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
@@ -178,29 +308,80 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.analysisProgress = current
 			m.analysisTotal = total
 			m.analysisWorker = workerName
+
+			var barCmd tea.Cmd
+			if total > 0 {
+				barCmd = m.progressBar.SetPercent(float64(current) / float64(total))
+			}
 			// Schedule next poll
-			return m, m.pollProgress()
+			return m, tea.Batch(m.pollProgress(), barCmd)
 		}
 
+	case deletionProgressMsg:
+		// Poll the deletion tracker like the analysis one
+		if m.state == stateDeleting && m.deletionTracker != nil {
+			current, total, resourceName := m.deletionTracker.get()
+			m.deletionProgress = current
+			m.deletionTotal = total
+			m.deletionResource = resourceName
+
+			var barCmd tea.Cmd
+			if total > 0 {
+				barCmd = m.progressBar.SetPercent(float64(current) / float64(total))
+			}
+			return m, tea.Batch(m.pollDeletionProgress(), barCmd)
+		}
+
+	case workerCountMsg:
+		m.workerCount = msg.count
+		return m, nil
+
+	case accountsLoadedMsg:
+		m.accountList = newAccountList(msg.accounts)
+		if m.windowWidth > 0 {
+			m.accountList.SetSize(m.windowWidth, m.windowHeight-2)
+		}
+		return m, nil
+
+	case accountsErrorMsg:
+		m.state = stateError
+		m.Err = msg.err
+		return m.quit()
+
 	case analysisCompleteMsg:
 		m.plan = msg.plan
 		m.state = stateShowPlan
+		m.syncPlanViewport()
 		return m, nil
 
 	case analysisErrorMsg:
 		m.state = stateError
 		m.Err = msg.err
-		return m, tea.Quit
+		return m.quit()
 
 	case deletionCompleteMsg:
-		m.state = stateComplete
 		m.Result = msg.result
-		return m, tea.Quit
+		if m.config.WaitForPropagation && !m.config.DryRun && m.Result.WorkerDeleted {
+			m.state = stateWaitingPropagation
+			return m, tea.Batch(m.spinner.Tick, m.awaitPropagation())
+		}
+		return m.showResult()
+
+	case propagationDoneMsg:
+		return m.showResult()
 
 	case deletionErrorMsg:
 		m.state = stateError
 		m.Err = msg.err
-		return m, tea.Quit
+		return m.quit()
+
+	case contextDoneMsg:
+		if m.done {
+			return m, nil
+		}
+		m.state = stateError
+		m.Err = msg.err
+		return m.quit()
 	}
 
 	return m, nil
@@ -208,7 +389,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Don't handle keys while deleting or analyzing
-	if m.state == stateDeleting || m.state == stateAnalyzing {
+	if m.state == stateDeleting || m.state == stateWaitingPropagation || m.state == stateAnalyzing {
 		return m, nil
 	}
 
@@ -221,12 +402,22 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleConfirmDeletionKeyPress(msg)
 	case stateConfirmShared:
 		return m.handleConfirmSharedKeyPress(msg)
+	case stateSelectAccount:
+		return m.handleSelectAccountKeyPress(msg)
+	case stateSelectResources:
+		return m.handleSelectResourcesKeyPress(msg)
+	case stateConfirmDanger:
+		return m.handleConfirmDangerKeyPress(msg)
+	case stateShowResult:
+		// Any key dismisses the result
+		m.state = stateComplete
+		return m.quit()
 	}
 
 	// Default: quit on ctrl+c or q
 	switch msg.String() {
 	case "ctrl+c", "q":
-		return m, tea.Quit
+		return m.quit()
 	}
 
 	return m, nil
@@ -235,7 +426,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleConfirmDependencyCheckKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q", "esc":
-		return m, tea.Quit
+		return m.quit()
 
 	case "n", "N":
 		// Skip dependency check
@@ -264,29 +455,74 @@ func (m Model) handleConfirmDependencyCheckKeyPress(msg tea.KeyMsg) (tea.Model,
 func (m Model) handlePlanKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q", "esc", "n", "N":
-		return m, tea.Quit
+		return m.quit()
 
-	case "y", "Y", "enter":
-		if m.config.AutoYes {
-			// Skip confirmations
-			return m.startDeletion()
+	case "up", "k":
+		m.moveResourceSelection(-1)
+		return m, nil
+
+	case "down", "j":
+		m.moveResourceSelection(1)
+		return m, nil
+
+	case "pgup", "pgdown", "home", "end":
+		var cmd tea.Cmd
+		m.planViewport, cmd = m.planViewport.Update(msg)
+		return m, cmd
+
+	case "enter":
+		// Show or hide every worker sharing the selected resource
+		if i, ok := m.selectedPlanResource(); ok {
+			if m.expandedResources == nil {
+				m.expandedResources = make(map[int]bool)
+			}
+			m.expandedResources[i] = !m.expandedResources[i]
+			m.syncPlanViewport()
 		}
-		m.state = stateConfirmDeletion
 		return m, nil
+
+	case "y", "Y":
+		if m.config.InteractiveSelect && len(m.plan.ResourcesToDelete) > 0 {
+			return m.startResourceSelection()
+		}
+		return m.confirmPlan()
 	}
 
 	return m, nil
 }
 
+// confirmPlan moves on from an accepted plan to the deletion confirmation
+func (m Model) confirmPlan() (tea.Model, tea.Cmd) {
+	if m.config.AutoYes {
+		// Skip confirmations
+		return m.startDeletion()
+	}
+	m.state = stateConfirmDeletion
+	return m, nil
+}
+
 func (m Model) handleConfirmDeletionKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q", "esc", "n", "N":
-		return m, tea.Quit
+		return m.quit()
 
 	case "y", "Y", "enter":
-		if m.plan.HasSharedResources && !m.config.ExclusiveOnly {
-			m.state = stateConfirmShared
-			return m, nil
+		// --force-delete-shared answers the shared resource prompts up front
+		if m.config.ForceDeleteShared {
+			m.plan.DeleteShared = true
+			return m.startDeletion()
+		}
+		if !m.config.ExclusiveOnly {
+			// Escalate the confirmation according to the worst shared resource
+			if m.plan.HasDangerResources() {
+				m.state = stateConfirmDanger
+				m.confirmInput = ""
+				return m, nil
+			}
+			if m.plan.HasCautionResources() {
+				m.state = stateConfirmShared
+				return m, nil
+			}
 		}
 		return m.startDeletion()
 	}
@@ -297,7 +533,7 @@ func (m Model) handleConfirmDeletionKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd
 func (m Model) handleConfirmSharedKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q", "esc":
-		return m, tea.Quit
+		return m.quit()
 
 	case "n", "N":
 		// Don't delete shared resources
@@ -314,13 +550,142 @@ func (m Model) handleConfirmSharedKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
+func (m Model) handleConfirmDangerKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m.quit()
+
+	case tea.KeyEnter:
+		if m.confirmInput == "" {
+			// Empty input keeps shared resources
+			m.skipShared = true
+			m.plan.DeleteShared = false
+			return m.startDeletion()
+		}
+		if m.confirmInput == m.worker.Name {
+			m.plan.DeleteShared = true
+			return m.startDeletion()
+		}
+		// Mismatch: clear and let the user try again
+		m.confirmInput = ""
+		return m, nil
+
+	case tea.KeyBackspace:
+		if len(m.confirmInput) > 0 {
+			m.confirmInput = m.confirmInput[:len(m.confirmInput)-1]
+		}
+		return m, nil
+
+	case tea.KeyRunes:
+		m.confirmInput += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// syncPlanViewport sizes the plan viewport to the terminal and refreshes its
+// content from the current plan
+func (m *Model) syncPlanViewport() {
+	if m.plan == nil {
+		return
+	}
+
+	sel := &views.PlanSelection{Selected: -1, Expanded: m.expandedResources}
+	if i, ok := m.selectedPlanResource(); ok {
+		sel.Selected = i
+	}
+	content, selectedLine := views.RenderDeletionPlanSelection(m.plan, sel, m.windowWidth)
+
+	// Shrink to fit short plans so the prompt sits right below them
+	height := m.windowHeight - lipgloss.Height(views.RenderHeaderWidth(m.windowWidth)) - planFooterLines
+	m.planViewport.Width = m.windowWidth
+	m.planViewport.Height = max(min(height, lipgloss.Height(content)), 1)
+	m.planViewport.SetContent(content)
+
+	// Keep the selected resource in view
+	if sel.Selected >= 0 {
+		if selectedLine < m.planViewport.YOffset {
+			m.planViewport.SetYOffset(selectedLine)
+		} else if selectedLine >= m.planViewport.YOffset+m.planViewport.Height {
+			m.planViewport.SetYOffset(selectedLine - m.planViewport.Height + 1)
+		}
+	}
+}
+
+// selectedPlanResource returns the plan index of the resource under the cursor
+func (m Model) selectedPlanResource() (int, bool) {
+	order := views.PlanResourceOrder(m.plan)
+	if m.selectedResource < 0 || m.selectedResource >= len(order) {
+		return 0, false
+	}
+	return order[m.selectedResource], true
+}
+
+// moveResourceSelection moves the plan cursor by delta resources
+func (m *Model) moveResourceSelection(delta int) {
+	count := len(m.plan.ResourcesToDelete)
+	if count == 0 {
+		return
+	}
+	m.selectedResource = min(max(m.selectedResource+delta, 0), count-1)
+	m.syncPlanViewport()
+}
+
+// quit marks the model as finished and stops the program
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.done = true
+	return m, tea.Quit
+}
+
+// Plan returns the deletion plan, or nil if analysis didn't finish
+func (m Model) Plan() *types.DeletionPlan {
+	return m.plan
+}
+
+// Outcome reports how the model's run ended
+func (m Model) Outcome() types.WorkerOutcome {
+	outcome := types.WorkerOutcome{
+		WorkerName: m.worker.Name,
+		Plan:       m.plan,
+		Result:     m.Result,
+		Err:        m.Err,
+	}
+
+	switch {
+	case m.Err != nil:
+		outcome.Status = types.OutcomeFailed
+	case m.Result == nil:
+		outcome.Status = types.OutcomeAborted
+	case m.Result.SkippedReason != "":
+		outcome.Status = types.OutcomeSkipped
+	case m.Result.Success:
+		outcome.Status = types.OutcomeDeleted
+	default:
+		outcome.Status = types.OutcomeFailed
+	}
+
+	return outcome
+}
+
+// ExitCode returns the process exit code for how the session ended
+func (m Model) ExitCode() int {
+	return exitcodes.ForResult(m.Result, m.Err)
+}
+
 func (m Model) startDeletion() (tea.Model, tea.Cmd) {
 	m.state = stateDeleting
+	// Reset the bar left full by the analysis
+	barCmd := m.progressBar.SetPercent(0)
+	tracker := &progressTracker{}
+	m.deletionTracker = tracker
 	return m, tea.Batch(
 		m.spinner.Tick,
+		barCmd,
+		m.pollDeletionProgress(),
 		func() tea.Msg {
 			// Execute deletion in background
-			result, err := m.deleter.Execute(m.plan)
+			result, err := m.deleter.ExecuteWithRollback(m.plan, tracker.update)
 			if err != nil {
 				return deletionErrorMsg{err: err}
 			}
@@ -329,57 +694,145 @@ func (m Model) startDeletion() (tea.Model, tea.Cmd) {
 	)
 }
 
+// awaitPropagation waits in the background for the deleted worker to stop
+// being served
+func (m Model) awaitPropagation() tea.Cmd {
+	return func() tea.Msg {
+		m.deleter.AwaitPropagation(m.Result, m.worker.Name, m.config.PropagationTimeout)
+		return propagationDoneMsg{}
+	}
+}
+
+// showResult finishes the deletion
+func (m Model) showResult() (tea.Model, tea.Cmd) {
+	// Keep the result on screen until the user dismisses it
+	m.state = stateShowResult
+	return m, nil
+}
+
+// renderPlan renders the whole deletion plan fitted to the terminal width
+func (m Model) renderPlan() string {
+	if m.windowWidth == 0 {
+		return views.RenderDeletionPlan(m.plan)
+	}
+	content, _ := views.RenderDeletionPlanSelection(m.plan, nil, m.windowWidth)
+	return content
+}
+
+// fitDetail truncates a progress detail line, the worker or resource being
+// processed, to fit below the progress bar
+func (m Model) fitDetail(detail string) string {
+	if m.windowWidth == 0 {
+		return detail
+	}
+	return views.Truncate(detail, max(m.windowWidth-progressDetailIndent, 1))
+}
+
 // View renders the UI
 func (m Model) View() string {
 	var b strings.Builder
 
-	b.WriteString(views.RenderHeader())
+	b.WriteString(views.RenderHeaderWidth(m.windowWidth))
 
 	switch m.state {
+	case stateSelectAccount:
+		if m.accountList.Items() == nil {
+			b.WriteString(views.RenderSpinner(m.spinner.View(), m.message, m.windowWidth))
+		} else {
+			b.WriteString(m.accountList.View())
+		}
+
+	case stateSelectResources:
+		b.WriteString(m.resourceList.View())
+		b.WriteString("\n\n")
+		b.WriteString(views.RenderMuted("↑/↓ move • space toggle • enter confirm • esc cancel"))
+
 	case stateLoading:
-		b.WriteString(fmt.Sprintf("%s %s\n", m.spinner.View(), m.message))
+		b.WriteString(views.RenderSpinner(m.spinner.View(), m.message, m.windowWidth))
 
 	case stateConfirmDependencyCheck:
 		b.WriteString(views.RenderWarning("Dependency analysis can take a long time on accounts with many workers."))
 		b.WriteString("\n\n")
 		b.WriteString("This checks if other workers share the same resources to prevent accidental deletion.\n")
 		b.WriteString("If you're certain no other workers use these resources, you can skip this step.\n\n")
-		b.WriteString("Run dependency analysis? [Y/n]: ")
+		if m.workerCount >= 0 {
+			b.WriteString(views.RenderAnalysisEstimate(m.workerCount, m.analyzer.EstimateAnalysisDuration(m.workerCount)))
+			b.WriteString(" Run full analysis? [Y/n]: ")
+		} else {
+			b.WriteString("Run dependency analysis? [Y/n]: ")
+		}
 
 	case stateAnalyzing:
-		b.WriteString(fmt.Sprintf("%s Analyzing dependencies...\n", m.spinner.View()))
+		b.WriteString(views.RenderSpinner(m.spinner.View(), "Analyzing dependencies...", m.windowWidth))
 		if m.analysisTotal > 0 {
-			percentage := float64(m.analysisProgress) / float64(m.analysisTotal) * 100
-			b.WriteString(fmt.Sprintf("   Progress: %d/%d workers (%.0f%%) - Current: %s\n",
-				m.analysisProgress, m.analysisTotal, percentage, m.analysisWorker))
+			b.WriteString(fmt.Sprintf("\n   %s %d/%d\n", m.progressBar.View(), m.analysisProgress, m.analysisTotal))
+			b.WriteString(fmt.Sprintf("   %s\n", views.RenderMuted(m.fitDetail(m.analysisWorker))))
 		}
 
 	case stateShowPlan:
-		b.WriteString(views.RenderDeletionPlan(m.plan))
+		// Until the terminal size is known there's nothing to scroll within
+		if m.windowHeight > 0 {
+			b.WriteString(m.planViewport.View())
+		} else {
+			b.WriteString(m.renderPlan())
+		}
 		b.WriteString("\n")
 		b.WriteString("Proceed with deletion? [y/N]: ")
+		b.WriteString("\n\n")
+		b.WriteString(views.RenderMuted("↑/↓ j/k select • enter show sharing workers • pgup/pgdown scroll • y proceed • n cancel"))
 
 	case stateConfirmDeletion:
-		b.WriteString(views.RenderDeletionPlan(m.plan))
+		b.WriteString(m.renderPlan())
 		b.WriteString("\n")
 		b.WriteString(views.RenderWarning("This action cannot be undone!"))
+		if databases := m.plan.NonEmptyDatabases(); len(databases) > 0 {
+			b.WriteString("\n\n")
+			b.WriteString(strings.TrimSuffix(views.RenderNonEmptyDatabases(databases), "\n"))
+		}
+		if m.plan.SkipWorkerDeletion {
+			b.WriteString("\n")
+			b.WriteString(views.RenderMuted("The worker script is kept; only its resources will be deleted."))
+		}
 		b.WriteString("\n\n")
 		b.WriteString("Are you sure? [y/N]: ")
 
 	case stateConfirmShared:
 		b.WriteString(views.RenderWarning("Shared resources will be deleted!"))
 		b.WriteString("\n\n")
-		b.WriteString("This may affect other workers. Continue? [y/N]: ")
+		b.WriteString("Some resources are used by 1-2 other workers. Delete them too? [y/N]: ")
+
+	case stateConfirmDanger:
+		b.WriteString(views.RenderDangerResources(m.plan))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("Type %s to delete shared resources, or press Enter to keep them (Esc to cancel):\n", views.RenderHighlight(m.worker.Name)))
+		b.WriteString(fmt.Sprintf("> %s", m.confirmInput))
 
 	case stateDeleting:
-		b.WriteString(fmt.Sprintf("%s Deleting resources...\n", m.spinner.View()))
+		b.WriteString(views.RenderSpinner(m.spinner.View(), "Deleting resources...", m.windowWidth))
+		if m.deletionTotal > 0 {
+			b.WriteString(fmt.Sprintf("\n   %s %d/%d\n", m.progressBar.View(), m.deletionProgress, m.deletionTotal))
+			b.WriteString(fmt.Sprintf("   %s\n", views.RenderMuted(m.fitDetail(m.deletionResource))))
+		}
+
+	case stateWaitingPropagation:
+		b.WriteString(views.RenderSpinner(m.spinner.View(), "Waiting for the deletion to propagate...", m.windowWidth))
+
+	case stateShowResult:
+		b.WriteString(views.RenderDeletionResult(m.Result))
+		b.WriteString("\n")
+		b.WriteString(views.RenderMuted("Press any key to exit"))
+		b.WriteString("\n")
 
 	case stateComplete:
 		b.WriteString(views.RenderDeletionResult(m.Result))
 		b.WriteString("\n")
 
 	case stateError:
-		b.WriteString(views.RenderError(fmt.Sprintf("Error: %v", m.Err)))
+		if errors.Is(m.Err, context.DeadlineExceeded) {
+			b.WriteString(views.RenderError("Timed out, no further changes were made"))
+		} else {
+			b.WriteString(views.RenderError(fmt.Sprintf("Error: %v", m.Err)))
+		}
 		b.WriteString("\n")
 	}
 
@@ -391,10 +844,16 @@ type deletionCompleteMsg struct {
 	result *types.DeletionResult
 }
 
+type propagationDoneMsg struct{}
+
 type deletionErrorMsg struct {
 	err error
 }
 
+type contextDoneMsg struct {
+	err error
+}
+
 type analysisProgressMsg struct {
 	current    int
 	total      int
@@ -410,3 +869,9 @@ type analysisErrorMsg struct {
 }
 
 type progressPollMsg struct{}
+
+type deletionProgressMsg struct{}
+
+type workerCountMsg struct {
+	count int
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+	"github.com/mattietk/cf-purge-worker/pkg/exitcodes"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// BulkModel runs a Model for each worker in turn and shows a summary at the end
+type BulkModel struct {
+	state    sessionState
+	models   []Model
+	current  int
+	failFast bool
+	Outcomes []types.WorkerOutcome
+}
+
+// NewBulkModel creates a model that processes the given worker models sequentially
+func NewBulkModel(models []Model) BulkModel {
+	state := stateAnalyzing
+	if len(models) == 0 {
+		state = stateSummary
+	}
+
+	return BulkModel{
+		state:  state,
+		models: models,
+	}
+}
+
+// WithFailFast returns the model set to stop at the first failed worker
+func (b BulkModel) WithFailFast(failFast bool) BulkModel {
+	b.failFast = failFast
+	return b
+}
+
+// Init initializes the first worker model
+func (b BulkModel) Init() tea.Cmd {
+	if b.state == stateSummary {
+		return nil
+	}
+	return b.models[0].Init()
+}
+
+// Update forwards messages to the active worker model and advances to the
+// next worker once it finishes
+func (b BulkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if b.state == stateSummary {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return b, tea.Quit
+		}
+		return b, nil
+	}
+
+	// ctrl+c aborts the whole batch, not just the current worker
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "ctrl+c" {
+		return b, tea.Quit
+	}
+
+	updated, cmd := b.models[b.current].Update(msg)
+	m := updated.(Model)
+	b.models[b.current] = m
+
+	if !m.done {
+		return b, cmd
+	}
+
+	// Drop the child's quit command and move on
+	outcome := m.Outcome()
+	b.Outcomes = append(b.Outcomes, outcome)
+	b.current++
+	if b.current >= len(b.models) || (b.failFast && outcome.Status == types.OutcomeFailed) {
+		b.state = stateSummary
+		return b, nil
+	}
+
+	return b, b.models[b.current].Init()
+}
+
+// View renders the active worker model or the final summary
+func (b BulkModel) View() string {
+	if b.state == stateSummary {
+		var sb strings.Builder
+		sb.WriteString(views.RenderHeader())
+		sb.WriteString(views.RenderBulkSummary(b.Outcomes))
+		sb.WriteString("\n")
+		sb.WriteString(views.RenderMuted("Press any key to exit"))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(views.RenderMuted(fmt.Sprintf("Worker %d of %d", b.current+1, len(b.models))))
+	sb.WriteString("\n")
+	sb.WriteString(b.models[b.current].View())
+	return sb.String()
+}
+
+// Batch returns the outcome of every worker. Workers that were never reached
+// count as aborted.
+func (b BulkModel) Batch() *types.BatchDeletionResult {
+	batch := &types.BatchDeletionResult{}
+	for _, outcome := range b.Outcomes {
+		batch.Add(outcome)
+	}
+	stoppedEarly := b.failFast && batch.WorstStatus() == types.OutcomeFailed
+	for _, m := range b.models[len(b.Outcomes):] {
+		outcome := types.WorkerOutcome{WorkerName: m.worker.Name, Status: types.OutcomeAborted}
+		if stoppedEarly {
+			outcome.Err = types.ErrSkippedAfterFailure
+		}
+		batch.Add(outcome)
+	}
+	return batch
+}
+
+// WorstOutcome returns the most severe outcome across all workers
+func (b BulkModel) WorstOutcome() types.OutcomeStatus {
+	return b.Batch().WorstStatus()
+}
+
+// ExitCode maps the worst outcome to a process exit code
+func (b BulkModel) ExitCode() int {
+	return exitcodes.ForStatus(b.WorstOutcome())
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattietk/cf-purge-worker/internal/ui/views"
+)
+
+// matchConfirmThreshold is the number of matched workers above which the user
+// must type the match count rather than just answer yes
+const matchConfirmThreshold = 5
+
+// MatchConfirmModel previews the workers matched by a name pattern and asks
+// the user to confirm before any of them are processed
+type MatchConfirmModel struct {
+	pattern      string
+	workers      []string
+	confirmInput string
+	Confirmed    bool
+}
+
+// NewMatchConfirmModel creates a confirmation model for the matched workers
+func NewMatchConfirmModel(pattern string, workers []string) MatchConfirmModel {
+	return MatchConfirmModel{
+		pattern: pattern,
+		workers: workers,
+	}
+}
+
+// Init initializes the model
+func (m MatchConfirmModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles key presses
+func (m MatchConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if len(m.workers) <= matchConfirmThreshold {
+		switch key.String() {
+		case "y", "Y":
+			m.Confirmed = true
+			return m, tea.Quit
+		case "n", "N", "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	// Larger matches need the count typed out
+	switch key.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+
+	case tea.KeyEnter:
+		if m.confirmInput == strconv.Itoa(len(m.workers)) {
+			m.Confirmed = true
+			return m, tea.Quit
+		}
+		// Mismatch: clear and let the user try again
+		m.confirmInput = ""
+		return m, nil
+
+	case tea.KeyBackspace:
+		if len(m.confirmInput) > 0 {
+			m.confirmInput = m.confirmInput[:len(m.confirmInput)-1]
+		}
+		return m, nil
+
+	case tea.KeyRunes:
+		m.confirmInput += string(key.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// View renders the matched workers and the confirmation prompt
+func (m MatchConfirmModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(views.RenderHeader())
+	b.WriteString("\n")
+	b.WriteString(views.RenderMatchedWorkers(m.pattern, m.workers))
+	b.WriteString("\n\n")
+
+	if len(m.workers) <= matchConfirmThreshold {
+		b.WriteString(fmt.Sprintf("Process these %d worker(s)? [y/N]: ", len(m.workers)))
+		return b.String()
+	}
+
+	b.WriteString(views.RenderWarning(fmt.Sprintf("More than %d workers match this pattern.", matchConfirmThreshold)))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Type %s to continue (Esc to cancel):\n", views.RenderHighlight(strconv.Itoa(len(m.workers)))))
+	b.WriteString(fmt.Sprintf("> %s", m.confirmInput))
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattietk/cf-purge-worker/internal/ui/styles"
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// Default size of the resource list until the terminal reports its size
+const (
+	resourceListWidth  = 72
+	resourceListHeight = 16
+)
+
+// resourceItem is a plan resource in the --interactive-select list
+type resourceItem struct {
+	resource types.ResourceUsage
+	checked  bool
+}
+
+func (i resourceItem) FilterValue() string { return i.resource.ResourceName }
+
+// resourceDelegate renders each resource as a checkbox with its risk indicator
+type resourceDelegate struct{}
+
+func (d resourceDelegate) Height() int                             { return 1 }
+func (d resourceDelegate) Spacing() int                            { return 0 }
+func (d resourceDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(resourceItem)
+	if !ok {
+		return
+	}
+
+	checkbox := "[ ]"
+	if i.checked {
+		checkbox = "[x]"
+	}
+	line := fmt.Sprintf("%s %s %s %s", checkbox, styles.RiskIndicator(i.resource.RiskLevel.String()),
+		i.resource.ResourceName, styles.Muted.Render(styles.FormatResourceType(string(i.resource.ResourceType))))
+
+	if index == m.Index() {
+		fmt.Fprint(w, styles.SelectedItem.Render("› "+line))
+		return
+	}
+	fmt.Fprint(w, styles.ListItem.Render("  "+line))
+}
+
+// newResourceList builds the checkbox list for the plan's resources, all checked
+func newResourceList(resources []types.ResourceUsage) list.Model {
+	items := make([]list.Item, 0, len(resources))
+	for _, resource := range resources {
+		items = append(items, resourceItem{resource: resource, checked: true})
+	}
+
+	l := list.New(items, resourceDelegate{}, resourceListWidth, resourceListHeight)
+	l.Title = "Select resources to delete"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+	return l
+}
+
+// startResourceSelection shows the checkbox list for --interactive-select
+func (m Model) startResourceSelection() (tea.Model, tea.Cmd) {
+	m.state = stateSelectResources
+	m.resourceList = newResourceList(m.plan.ResourcesToDelete)
+	if m.windowWidth > 0 {
+		m.resourceList.SetSize(m.windowWidth, m.windowHeight-2)
+	}
+	return m, nil
+}
+
+func (m Model) handleSelectResourcesKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		return m.quit()
+
+	case " ":
+		index := m.resourceList.Index()
+		if item, ok := m.resourceList.SelectedItem().(resourceItem); ok {
+			item.checked = !item.checked
+			return m, m.resourceList.SetItem(index, item)
+		}
+		return m, nil
+
+	case "enter":
+		m.applyResourceSelection()
+		return m.confirmPlan()
+	}
+
+	var cmd tea.Cmd
+	m.resourceList, cmd = m.resourceList.Update(msg)
+	return m, cmd
+}
+
+// applyResourceSelection keeps only the checked resources in the plan. The
+// unchecked ones are listed as excluded.
+func (m *Model) applyResourceSelection() {
+	var selected []types.ResourceUsage
+	for _, item := range m.resourceList.Items() {
+		i, ok := item.(resourceItem)
+		if !ok {
+			continue
+		}
+		if i.checked {
+			selected = append(selected, i.resource)
+		} else {
+			m.plan.ResourcesExcluded = append(m.plan.ResourcesExcluded, i.resource.ResourceName)
+		}
+	}
+	m.plan.ResourcesToDelete = selected
+	m.syncPlanViewport()
+}
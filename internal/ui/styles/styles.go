@@ -2,6 +2,7 @@ package styles
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Cloudflare color palette
@@ -106,6 +107,29 @@ var (
 		Bold(true)
 )
 
+// DisableColors strips colors and text attributes from every style, for
+// --no-color, NO_COLOR and dumb terminals. Borders and spacing are kept.
+func DisableColors() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	for _, c := range []*lipgloss.Color{&Orange, &DarkBlue, &LightBlue, &Green, &Yellow, &Red, &White, &Gray, &DarkGray} {
+		*c = ""
+	}
+	for _, s := range []*lipgloss.Style{
+		&Title, &Subtitle, &Header, &Section, &Info, &Success, &Warning, &Error, &Danger, &Muted, &Highlight,
+		&Box, &WarningBox, &DangerBox, &SuccessBox,
+		&ListItem, &SelectedItem,
+	} {
+		*s = s.UnsetForeground().UnsetBackground().UnsetBorderForeground().UnsetBold()
+	}
+}
+
+// ColorProfile returns the color profile output is rendered with, for
+// components that colour their own output
+func ColorProfile() termenv.Profile {
+	return lipgloss.ColorProfile()
+}
+
 // Risk indicator styles
 func RiskIndicator(level string) string {
 	switch level {
@@ -115,6 +139,8 @@ func RiskIndicator(level string) string {
 		return Warning.Render("🟡")
 	case "danger":
 		return Danger.Render("🔴")
+	case "unknown":
+		return Muted.Render("⬜")
 	default:
 		return Muted.Render("⚪")
 	}
@@ -139,6 +165,24 @@ func FormatResourceType(resourceType string) string {
 		return "Hyperdrive"
 	case "vectorize":
 		return "Vectorize Index"
+	case "mtls_certificate":
+		return "mTLS Certificate"
+	case "dispatch_namespace":
+		return "Dispatch Namespace"
+	case "version_metadata":
+		return "Version Metadata"
+	case "ai":
+		return "Workers AI"
+	case "browser":
+		return "Browser Rendering"
+	case "analytics_engine":
+		return "Analytics Engine"
+	case "plain_text":
+		return "Environment Variable"
+	case "secret_text":
+		return "Secret"
+	case "unknown":
+		return "Unknown"
 	default:
 		return resourceType
 	}
@@ -0,0 +1,56 @@
+package views
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+)
+
+// minNameWidth keeps truncated names readable on very narrow terminals
+const minNameWidth = 12
+
+// TerminalWidth returns the width of the terminal stdout is attached to,
+// falling back to $COLUMNS when stdout isn't a terminal. It returns 0 when
+// the width is unknown, which renders without a width limit.
+func TerminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// Truncate shortens s to at most width cells, ending it with an ellipsis. A
+// width of 0 leaves s unchanged.
+func Truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+
+	var (
+		out  []rune
+		used int
+	)
+	for _, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > width-1 {
+			break
+		}
+		out = append(out, r)
+		used += w
+	}
+	return string(out) + "…"
+}
+
+// fitBox caps a box at width cells, wrapping content too wide for it. A
+// width of 0 leaves the box unchanged.
+func fitBox(box lipgloss.Style, content string, width int) lipgloss.Style {
+	if width <= 0 || lipgloss.Width(content)+box.GetHorizontalFrameSize() <= width {
+		return box
+	}
+	return box.Width(width - box.GetHorizontalBorderSize())
+}
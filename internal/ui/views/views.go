@@ -1,23 +1,53 @@
 package views
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/mattietk/cf-purge-worker/internal/ui/styles"
+	"github.com/mattietk/cf-purge-worker/pkg/diff"
 	"github.com/mattietk/cf-purge-worker/pkg/types"
 )
 
+// recentActivityWindow is how recently a worker must have served a request
+// for the plan to warn that it may still be in use
+const recentActivityWindow = 24 * time.Hour
+
+// maxRecentErrors is the number of recent error events shown in worker details
+const maxRecentErrors = 3
+
 // RenderHeader renders the application header
 func RenderHeader() string {
+	return RenderHeaderWidth(TerminalWidth())
+}
+
+// RenderHeaderWidth renders the application header fitted to width cells, 0
+// renders without a limit
+func RenderHeaderWidth(width int) string {
 	var b strings.Builder
-	b.WriteString(styles.Header.Render("☁️  cf-purge-worker"))
+	title := "☁️  cf-purge-worker"
+	b.WriteString(fitBox(styles.Header, title, width).Render(title))
 	b.WriteString("\n")
-	b.WriteString(styles.Subtitle.Render("Safely delete Cloudflare Workers and resources"))
+	b.WriteString(styles.Subtitle.Render(Truncate("Safely delete Cloudflare Workers and resources", width)))
 	b.WriteString("\n")
 	return b.String()
 }
 
+// RenderSpinner renders a spinner frame followed by message, truncated to
+// fit width cells
+func RenderSpinner(frame, message string, width int) string {
+	if width > 0 {
+		message = Truncate(message, max(width-lipgloss.Width(frame)-1, 1))
+	}
+	return fmt.Sprintf("%s %s\n", frame, message)
+}
+
 // RenderWorkerInfo renders worker information
 func RenderWorkerInfo(worker *types.WorkerInfo) string {
 	var b strings.Builder
@@ -25,6 +55,9 @@ func RenderWorkerInfo(worker *types.WorkerInfo) string {
 	b.WriteString(styles.Section.Render("📦 Worker Details"))
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("  Name: %s\n", styles.Highlight.Render(worker.Name)))
+	if worker.SubdomainURL != "" {
+		b.WriteString(fmt.Sprintf("  URL: %s\n", styles.Info.Render(worker.SubdomainURL)))
+	}
 
 	if !worker.CreatedOn.IsZero() {
 		b.WriteString(fmt.Sprintf("  Created: %s\n", styles.Info.Render(worker.CreatedOn.Format("2006-01-02"))))
@@ -32,73 +65,707 @@ func RenderWorkerInfo(worker *types.WorkerInfo) string {
 	if !worker.ModifiedOn.IsZero() {
 		b.WriteString(fmt.Sprintf("  Modified: %s\n", styles.Info.Render(worker.ModifiedOn.Format("2006-01-02"))))
 	}
+	if worker.ScriptSize > 0 {
+		b.WriteString(fmt.Sprintf("  Script Size: %s\n", styles.Info.Render(formatBytes(worker.ScriptSize))))
+	}
 
+	if worker.UsageModel != "" {
+		b.WriteString(fmt.Sprintf("  Usage Model: %s\n", styles.Info.Render(worker.UsageModel)))
+	}
+
+	if worker.Metrics != nil {
+		b.WriteString(fmt.Sprintf("  Last active: %s\n", styles.Info.Render(formatLastActive(worker.Metrics))))
+	}
 	b.WriteString(fmt.Sprintf("  Bindings: %s\n", styles.Info.Render(fmt.Sprintf("%d", len(worker.Bindings)))))
+	if len(worker.Tags) > 0 {
+		b.WriteString(fmt.Sprintf("  Tags: %s\n", styles.Info.Render(strings.Join(worker.Tags, ", "))))
+	}
+
+	if len(worker.RecentErrors) > 0 {
+		b.WriteString("  Recent errors:\n")
+		for i, event := range worker.RecentErrors {
+			if i >= maxRecentErrors {
+				break
+			}
+			b.WriteString(fmt.Sprintf("    %s %s %s\n",
+				styles.Muted.Render(event.Timestamp.Format("2006-01-02 15:04:05")),
+				styles.Error.Render(event.Outcome),
+				styles.Muted.Render(fmt.Sprintf("(cpu %dµs)", event.CPUTime))))
+			if event.ExceptionMessage != "" {
+				b.WriteString(fmt.Sprintf("      %s\n", event.ExceptionMessage))
+			}
+		}
+	}
 	b.WriteString("\n")
 
 	return b.String()
 }
 
-// RenderDeletionPlan renders the deletion plan
-func RenderDeletionPlan(plan *types.DeletionPlan) string {
+// RenderTokenInfo renders the details of an API token
+func RenderTokenInfo(info *types.TokenInfo) string {
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("API Token"))
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("ID: %s\n", info.ID))
+	if info.Name != "" {
+		b.WriteString(fmt.Sprintf("Name: %s\n", styles.Highlight.Render(info.Name)))
+	}
+	b.WriteString(fmt.Sprintf("Status: %s\n", info.Status))
+	if info.OwnerEmail != "" {
+		b.WriteString(fmt.Sprintf("Owner: %s\n", info.OwnerEmail))
+	}
+	if !info.ExpiresOn.IsZero() {
+		b.WriteString(fmt.Sprintf("Expires: %s\n", info.ExpiresOn.Format("2006-01-02")))
+	}
+
+	if len(info.Permissions) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.Section.Render("Permissions:"))
+		b.WriteString("\n")
+		for _, permission := range info.Permissions {
+			b.WriteString(fmt.Sprintf("  • %s\n", permission))
+		}
+	}
+
+	return styles.Box.Render(b.String())
+}
+
+// RenderWorkerBindings renders a table of a worker's bindings grouped by
+// type. Verbose adds the IDs and names that identify each resource.
+func RenderWorkerBindings(worker *types.WorkerInfo, verbose bool) string {
 	var b strings.Builder
 
-	b.WriteString(styles.Box.Render(buildDeletionPlanContent(plan)))
+	b.WriteString(RenderWorkerInfo(worker))
+	if len(worker.Bindings) == 0 {
+		b.WriteString(styles.Muted.Render("No bindings"))
+		return b.String()
+	}
+
+	bindings := slices.Clone(worker.Bindings)
+	slices.SortStableFunc(bindings, func(x, y types.Binding) int {
+		if c := cmp.Compare(slices.Index(types.Categories, x.Type.Category()), slices.Index(types.Categories, y.Type.Category())); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(x.Type, y.Type); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Name, y.Name)
+	})
+
+	typeWidth, nameWidth := len("TYPE"), len("BINDING")
+	for _, binding := range bindings {
+		typeWidth = max(typeWidth, len(styles.FormatResourceType(string(binding.Type))))
+		nameWidth = max(nameWidth, len(binding.Name))
+	}
+
+	header := fmt.Sprintf("%-*s  %-*s  %s", typeWidth, "TYPE", nameWidth, "BINDING", "RESOURCE")
+	if verbose {
+		header += "  DETAILS"
+	}
+	b.WriteString(styles.Section.Render(header))
+	b.WriteString("\n")
+
+	var lastType types.BindingType
+	for _, binding := range bindings {
+		typeName := ""
+		if binding.Type != lastType {
+			typeName = styles.FormatResourceType(string(binding.Type))
+			lastType = binding.Type
+		}
+		line := fmt.Sprintf("%-*s  %-*s  %s", typeWidth, typeName, nameWidth, binding.Name, bindingResource(binding))
+		if verbose {
+			if details := bindingDetails(binding); details != "" {
+				line += "  " + styles.Muted.Render(details)
+			}
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString(styles.Muted.Render(fmt.Sprintf("%d binding(s)", len(bindings))))
+
 	return b.String()
 }
 
-func buildDeletionPlanContent(plan *types.DeletionPlan) string {
+// bindingResource returns the name of the resource a binding points at
+func bindingResource(binding types.Binding) string {
+	switch binding.Type {
+	case types.BindingTypeKV:
+		return cmp.Or(binding.NamespaceName, binding.NamespaceID)
+	case types.BindingTypeR2:
+		return binding.BucketName
+	case types.BindingTypeD1:
+		return cmp.Or(binding.DatabaseName, binding.DatabaseID)
+	case types.BindingTypeDurableObject:
+		return binding.ClassName
+	case types.BindingTypeService:
+		return binding.ScriptName
+	case types.BindingTypeQueue:
+		return binding.QueueName
+	case types.BindingTypeHyperdrive:
+		return binding.ConfigID
+	case types.BindingTypeVectorize:
+		return binding.IndexName
+	case types.BindingTypeMTLS:
+		return binding.CertificateID
+	case types.BindingTypeAnalyticsEngine:
+		return binding.DatasetName
+	case types.BindingTypeUnknown:
+		return "type " + binding.RawType
+	default:
+		return "-"
+	}
+}
+
+// bindingDetails lists the type-specific fields set on a binding
+func bindingDetails(binding types.Binding) string {
+	fields := []struct{ name, value string }{
+		{"namespace_id", binding.NamespaceID},
+		{"bucket_name", binding.BucketName},
+		{"database_id", binding.DatabaseID},
+		{"class_name", binding.ClassName},
+		{"script_name", binding.ScriptName},
+		{"queue_name", binding.QueueName},
+		{"config_id", binding.ConfigID},
+		{"index_name", binding.IndexName},
+		{"certificate_id", binding.CertificateID},
+		{"dataset", binding.DatasetName},
+	}
+
+	var details []string
+	for _, field := range fields {
+		if field.value != "" {
+			details = append(details, field.name+"="+field.value)
+		}
+	}
+	return strings.Join(details, " ")
+}
+
+// RenderMatchedWorkers renders the workers matched by a name pattern
+func RenderMatchedWorkers(pattern string, workers []string) string {
+	var b strings.Builder
+
+	b.WriteString(styles.Section.Render(fmt.Sprintf("%d worker(s) match %s:", len(workers), styles.Highlight.Render(pattern))))
+	b.WriteString("\n")
+	for _, name := range workers {
+		b.WriteString(fmt.Sprintf("  • %s\n", name))
+	}
+
+	return styles.Box.Render(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// RenderWorkerList renders a table of workers with their dates and binding counts
+func RenderWorkerList(workers []types.WorkerInfo) string {
+	var b strings.Builder
+
+	if len(workers) == 0 {
+		b.WriteString(styles.Muted.Render("No workers found"))
+		return b.String()
+	}
+
+	nameWidth := len("NAME")
+	for _, w := range workers {
+		if len(w.Name) > nameWidth {
+			nameWidth = len(w.Name)
+		}
+	}
+
+	b.WriteString(styles.Section.Render(fmt.Sprintf("%-*s  %-10s  %-10s  %s", nameWidth, "NAME", "CREATED", "MODIFIED", "BINDINGS")))
+	b.WriteString("\n")
+	for _, w := range workers {
+		b.WriteString(fmt.Sprintf("%-*s  %-10s  %-10s  %d\n",
+			nameWidth, w.Name, formatDate(w.CreatedOn), formatDate(w.ModifiedOn), len(w.Bindings)))
+	}
+	b.WriteString(styles.Muted.Render(fmt.Sprintf("%d worker(s)", len(workers))))
+
+	return b.String()
+}
+
+// sharingWorkersShown is how many sharing workers are named before the rest
+// are summarised as "+N more"
+const sharingWorkersShown = 5
+
+// PlanSelection is the cursor state of the interactive plan view. Indexes
+// refer to the plan's ResourcesToDelete.
+type PlanSelection struct {
+	Selected int
+	Expanded map[int]bool
+}
+
+// RenderDeletionPlan renders the deletion plan to fit the terminal
+func RenderDeletionPlan(plan *types.DeletionPlan) string {
+	content, _ := RenderDeletionPlanSelection(plan, nil, TerminalWidth())
+	return content
+}
+
+// RenderDeletionPlanSelection renders the deletion plan with the selected
+// resource highlighted and the full list of sharing workers for expanded
+// resources. It also returns the line the selected resource is on. Long names
+// are truncated to fit width cells, 0 renders without a limit.
+func RenderDeletionPlanSelection(plan *types.DeletionPlan, sel *PlanSelection, width int) (string, int) {
+	contentWidth := 0
+	if width > 0 {
+		contentWidth = max(width-styles.Box.GetHorizontalFrameSize(), minNameWidth)
+	}
+	content, line := buildDeletionPlanContent(plan, sel, contentWidth)
+	offset := styles.Box.GetMarginTop() + styles.Box.GetBorderTopSize() + styles.Box.GetPaddingTop()
+	return fitBox(styles.Box, content, width).Render(content), line + offset
+}
+
+// PlanResourceOrder returns the indexes of the plan's resources in the order
+// the plan view lists them
+func PlanResourceOrder(plan *types.DeletionPlan) []int {
+	var order []int
+	grouped := groupResourcesByCategory(plan.ResourcesToDelete)
+	for _, category := range types.Categories {
+		for _, resourceType := range sortedResourceTypes(grouped[category]) {
+			order = append(order, grouped[category][resourceType]...)
+		}
+	}
+	return order
+}
+
+// buildDeletionPlanContent renders the inside of the plan box. Names are
+// truncated to fit width cells when it is set.
+func buildDeletionPlanContent(plan *types.DeletionPlan, sel *PlanSelection, width int) (string, int) {
 	var b strings.Builder
+	selectedLine := 0
+
+	// Room left for a name after the label or the resource line's cursor
+	// and risk indicator
+	workerNameWidth, resourceNameWidth := 0, 0
+	if width > 0 {
+		workerNameWidth = max(width-len("Worker: "), minNameWidth)
+		resourceNameWidth = max(width-lipgloss.Width("  › 🟢 "), minNameWidth)
+	}
 
 	b.WriteString(styles.Title.Render("Deletion Plan"))
 	b.WriteString("\n\n")
 
 	// Worker info
-	b.WriteString(fmt.Sprintf("Worker: %s\n", styles.Highlight.Render(plan.Worker.Name)))
+	b.WriteString(fmt.Sprintf("Worker: %s\n", styles.Highlight.Render(Truncate(plan.Worker.Name, workerNameWidth))))
+	if plan.Worker.SubdomainURL != "" {
+		b.WriteString(fmt.Sprintf("URL: %s\n", Truncate(plan.Worker.SubdomainURL, workerNameWidth)))
+	}
 	if !plan.Worker.ModifiedOn.IsZero() {
 		b.WriteString(fmt.Sprintf("Last Modified: %s\n", plan.Worker.ModifiedOn.Format("2006-01-02")))
 	}
+	if plan.Worker.ScriptSize > 0 {
+		b.WriteString(fmt.Sprintf("Script Size: %s\n", formatBytes(plan.Worker.ScriptSize)))
+	}
+	if plan.Worker.UsageModel != "" {
+		b.WriteString(fmt.Sprintf("Usage Model: %s\n", plan.Worker.UsageModel))
+	}
+	if plan.SkipWorkerDeletion {
+		b.WriteString(styles.Success.Render("Worker script will be preserved"))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
 
-	// Group resources by type
-	resourcesByType := groupResourcesByType(plan.ResourcesToDelete)
+	if len(plan.IncludeTypes) > 0 {
+		names := make([]string, 0, len(plan.IncludeTypes))
+		for _, t := range plan.IncludeTypes {
+			names = append(names, styles.FormatResourceType(string(t)))
+		}
+		b.WriteString(styles.Muted.Render(fmt.Sprintf("Only deleting: %s", strings.Join(names, ", "))))
+		b.WriteString("\n\n")
+	}
+	if plan.TagFilter != "" {
+		b.WriteString(styles.Muted.Render(fmt.Sprintf("Only deleting resources tagged: %s", plan.TagFilter)))
+		b.WriteString("\n\n")
+	}
+	if plan.RegionFilter != "" {
+		b.WriteString(styles.Muted.Render(fmt.Sprintf("Only deleting R2 buckets in jurisdiction: %s", plan.RegionFilter)))
+		b.WriteString("\n\n")
+	}
+
+	// Group resources by category, then by type within each category
+	resourcesByCategory := groupResourcesByCategory(plan.ResourcesToDelete)
 
-	if len(resourcesByType) == 0 {
+	if len(resourcesByCategory) == 0 {
 		b.WriteString(styles.Muted.Render("No resources to delete"))
 		b.WriteString("\n")
 	} else {
 		b.WriteString(styles.Section.Render("Resources to Delete:"))
 		b.WriteString("\n\n")
 
-		for resourceType, resources := range resourcesByType {
-			b.WriteString(fmt.Sprintf("%s (%d):\n", styles.FormatResourceType(string(resourceType)), len(resources)))
-			for _, resource := range resources {
-				indicator := getRiskIndicator(resource.RiskLevel)
-				b.WriteString(fmt.Sprintf("  %s %s", indicator, resource.ResourceName))
+		for _, category := range types.Categories {
+			resourcesByType, ok := resourcesByCategory[category]
+			if !ok {
+				continue
+			}
+
+			b.WriteString(fmt.Sprintf("%s:\n", styles.Highlight.Render(category)))
+			for _, resourceType := range sortedResourceTypes(resourcesByType) {
+				indexes := resourcesByType[resourceType]
+				b.WriteString(fmt.Sprintf("  %s (%d):\n", styles.FormatResourceType(string(resourceType)), len(indexes)))
+				for _, i := range indexes {
+					resource := plan.ResourcesToDelete[i]
+					indicator := getRiskIndicator(resource.RiskLevel)
+
+					cursor := " "
+					name := Truncate(resource.ResourceName, resourceNameWidth)
+					if sel != nil && sel.Selected == i {
+						selectedLine = strings.Count(b.String(), "\n")
+						cursor = styles.Highlight.Render("›")
+						name = styles.Highlight.Render(name)
+					}
+
+					var line strings.Builder
+					line.WriteString(fmt.Sprintf("  %s %s %s", cursor, indicator, name))
+					if resource.Location != "" {
+						line.WriteString(fmt.Sprintf(" %s", styles.Muted.Render(fmt.Sprintf("(%s)", resource.Location))))
+					}
+					if resource.Jurisdiction != "" {
+						line.WriteString(fmt.Sprintf(" %s", styles.Muted.Render(fmt.Sprintf("[%s jurisdiction]", resource.Jurisdiction))))
+					}
+					if resource.StorageBytes != nil && *resource.StorageBytes > 0 {
+						usage := formatBytes(*resource.StorageBytes)
+						if resource.ObjectCount > 0 {
+							usage += ", " + formatObjects(resource.ObjectCount)
+						}
+						line.WriteString(fmt.Sprintf(" %s", styles.Danger.Render(fmt.Sprintf("(%s)", usage))))
+					}
+					if resource.KeyCount != nil && *resource.KeyCount > 0 {
+						line.WriteString(fmt.Sprintf(" %s", styles.Muted.Render(fmt.Sprintf("(%s)", formatKeyCount(*resource.KeyCount)))))
+					}
+					if resource.TableCount != nil {
+						line.WriteString(fmt.Sprintf(" %s", styles.Muted.Render(fmt.Sprintf("(%s)", formatTableCount(*resource.TableCount)))))
+					}
+					if resource.Backlog > 0 {
+						line.WriteString(fmt.Sprintf(" %s", styles.Warning.Render(fmt.Sprintf("(%s waiting)", formatMessages(resource.Backlog)))))
+					}
+					if len(resource.Tags) > 0 {
+						line.WriteString(fmt.Sprintf(" %s", styles.Muted.Render("#"+strings.Join(resource.Tags, " #"))))
+					}
+					if resource.HasOtherConsumers(plan.Worker.Name) {
+						line.WriteString(fmt.Sprintf(" %s", styles.Danger.Render(fmt.Sprintf("(consumed by %s)", summarizeWorkers(getOtherWorkers(resource.Consumers, plan.Worker.Name))))))
+					}
+					b.WriteString(line.String())
 
-				// Show which other workers use this
-				if resource.RiskLevel != types.RiskLevelSafe {
-					otherWorkers := getOtherWorkers(resource.UsedBy, plan.Worker.Name)
+					// Show which other workers use this
+					var otherWorkers []string
+					if resource.RiskLevel != types.RiskLevelSafe {
+						otherWorkers = getOtherWorkers(resource.UsedBy, plan.Worker.Name)
+					}
+					expanded := sel != nil && sel.Expanded[i]
 					if len(otherWorkers) > 0 {
-						b.WriteString(fmt.Sprintf(" %s", styles.Warning.Render(fmt.Sprintf("(used by %d other worker(s))", len(otherWorkers)))))
+						usage := fmt.Sprintf("(used by %d other worker(s))", len(otherWorkers))
+						if !expanded {
+							usage = fmt.Sprintf("(used by %d other worker(s): %s)", len(otherWorkers), summarizeWorkers(otherWorkers))
+						}
+						// Move the detail under the name when the line would overflow
+						if width > 0 && lipgloss.Width(line.String())+1+lipgloss.Width(usage) > width {
+							b.WriteString(fmt.Sprintf("\n      %s", styles.Warning.Render(usage)))
+						} else {
+							b.WriteString(fmt.Sprintf(" %s", styles.Warning.Render(usage)))
+						}
+					}
+					b.WriteString("\n")
+
+					if expanded {
+						for _, worker := range otherWorkers {
+							b.WriteString(fmt.Sprintf("        %s\n", styles.Muted.Render("↳ "+worker)))
+						}
 					}
 				}
-				b.WriteString("\n")
 			}
 			b.WriteString("\n")
 		}
 	}
 
+	if len(plan.ResourcesExcluded) > 0 {
+		b.WriteString(styles.Section.Render(fmt.Sprintf("Excluded (%d):", len(plan.ResourcesExcluded))))
+		b.WriteString("\n")
+		for _, name := range plan.ResourcesExcluded {
+			b.WriteString(fmt.Sprintf("  %s\n", styles.Muted.Render(name)))
+		}
+		b.WriteString("\n")
+	}
+
+	if skipped := len(plan.ResourcesSkippedByType) + len(plan.ResourcesSkippedEmpty); skipped > 0 {
+		b.WriteString(styles.Section.Render(fmt.Sprintf("Skipped (%d):", skipped)))
+		b.WriteString("\n")
+		for _, resource := range plan.ResourcesSkippedByType {
+			b.WriteString(fmt.Sprintf("  %s %s\n",
+				styles.Muted.Render(fmt.Sprintf("%s %s", styles.FormatResourceType(string(resource.ResourceType)), resource.ResourceName)),
+				styles.Muted.Render("skipped (type excluded)")))
+		}
+		for _, resource := range plan.ResourcesSkippedEmpty {
+			b.WriteString(fmt.Sprintf("  %s %s\n",
+				styles.Muted.Render(fmt.Sprintf("%s %s", styles.FormatResourceType(string(resource.ResourceType)), resource.ResourceName)),
+				styles.Muted.Render("skipped (empty)")))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(plan.Worker.CronTriggers) > 0 && !plan.SkipWorkerDeletion {
+		b.WriteString(styles.Section.Render(fmt.Sprintf("Cron Triggers to Remove (%d):", len(plan.Worker.CronTriggers))))
+		b.WriteString("\n")
+		for _, trigger := range plan.Worker.CronTriggers {
+			b.WriteString(fmt.Sprintf("  🕒 %s\n", trigger.Cron))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(plan.Worker.UnknownBindings) > 0 {
+		b.WriteString(styles.Section.Render("Unknown binding types (manual review required):"))
+		b.WriteString("\n")
+		for _, binding := range plan.Worker.UnknownBindings {
+			b.WriteString(fmt.Sprintf("  ❓ %s %s\n", binding.Name, styles.Muted.Render(fmt.Sprintf("(%s)", binding.RawType))))
+		}
+		b.WriteString(styles.Muted.Render("Resources behind these bindings are not deleted, check them in the dashboard"))
+		b.WriteString("\n\n")
+	}
+
+	if secrets := plan.Worker.SecretNames(); len(secrets) > 0 {
+		switch {
+		case !plan.SkipWorkerDeletion:
+			b.WriteString(styles.Section.Render(fmt.Sprintf("Secrets to Delete (%d):", len(secrets))))
+			b.WriteString("\n")
+			for _, name := range secrets {
+				b.WriteString(fmt.Sprintf("  🔑 %s %s\n", name, styles.Muted.Render("(deleted with worker)")))
+			}
+		case plan.DeleteSecrets:
+			b.WriteString(styles.Section.Render(fmt.Sprintf("Secrets to Delete (%d):", len(secrets))))
+			b.WriteString("\n")
+			for _, name := range secrets {
+				b.WriteString(fmt.Sprintf("  🔑 %s\n", name))
+			}
+		default:
+			b.WriteString(styles.Muted.Render(fmt.Sprintf("%d secret(s) are kept with the worker, use --delete-secrets to remove them", len(secrets))))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if plan.RouteLookupError != "" {
+		b.WriteString(styles.Warning.Render("⚠ Some routes could not be listed and may be left behind: " + plan.RouteLookupError))
+		b.WriteString("\n")
+		if plan.DeleteRoutes {
+			b.WriteString(styles.Muted.Render("--delete-routes is refused until every route can be listed"))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(plan.Routes) > 0 {
+		b.WriteString(RenderWorkerRoutes(plan.Routes))
+		if plan.DeleteRoutes {
+			b.WriteString(styles.Muted.Render("Routes will be deleted before the worker"))
+		} else {
+			b.WriteString(styles.Muted.Render("Routes are kept, use --delete-routes to remove them"))
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(styles.Muted.Render(fmt.Sprintf("Estimated time: ~%s", formatEstimate(plan.EstimatedDuration()))))
+	b.WriteString("\n")
+	if storage := plan.Summary().TotalResourceStorageEstimate; !storage.IsZero() {
+		b.WriteString(styles.Muted.Render(fmt.Sprintf("Data held: %s", formatStorageEstimate(storage))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
 	// Warnings
-	if plan.HasSharedResources {
+	if sharedCount := plan.Summary().SharedResources(); sharedCount > 0 {
 		b.WriteString(styles.Warning.Render("⚠️  Warning: "))
-		sharedCount := countSharedResources(plan.ResourcesToDelete)
 		b.WriteString(fmt.Sprintf("%d shared resource(s) detected\n", sharedCount))
 	}
+	if databases := plan.NonEmptyDatabases(); len(databases) > 0 {
+		b.WriteString(styles.Warning.Render("⚠️  Warning: "))
+		b.WriteString(fmt.Sprintf("%d D1 database(s) have tables, their data will be lost\n", len(databases)))
+	}
+	if buckets := plan.NonEmptyBuckets(); len(buckets) > 0 {
+		var (
+			objects int
+			size    int64
+		)
+		for _, bucket := range buckets {
+			objects += bucket.ObjectCount
+			if bucket.StorageBytes != nil {
+				size += *bucket.StorageBytes
+			}
+		}
+		b.WriteString(styles.Warning.Render("⚠️  Warning: "))
+		if plan.EmptyR2Buckets {
+			b.WriteString(fmt.Sprintf("%d R2 bucket(s) will be emptied first: %s (%s) will be lost\n",
+				len(buckets), formatObjects(objects), formatBytes(size)))
+		} else {
+			b.WriteString(fmt.Sprintf("%d R2 bucket(s) hold data and can't be deleted until emptied, use --empty-r2-before-delete\n",
+				len(buckets)))
+		}
+	}
+	if metrics := plan.Worker.Metrics; metrics.ActiveWithin(recentActivityWindow) {
+		b.WriteString(styles.Warning.Render("⚠️  Warning: "))
+		b.WriteString(fmt.Sprintf("%s served a request %s, it may still be in use\n",
+			plan.Worker.Name, formatAgo(metrics.LastRequestAt)))
+	}
+	if len(plan.SkippedWorkers) > 0 {
+		b.WriteString(styles.Warning.Render("⚠️  Warning: "))
+		b.WriteString(fmt.Sprintf("%d worker(s) could not be checked, resources marked %s may be shared\n",
+			len(plan.SkippedWorkers), getRiskIndicator(types.RiskLevelUnknown)))
+	}
+	if plan.AnalysisTruncated {
+		b.WriteString(styles.Warning.Render("⚠️  Warning: "))
+		b.WriteString(fmt.Sprintf("Analysis limited to %d workers; results may be incomplete.\n", plan.AnalysisLimit))
+	}
 
+	return b.String(), selectedLine
+}
+
+// RenderExclusivityCheck renders the result of --assert-exclusive: the shared
+// resources and the other workers using them, or a success line
+func RenderExclusivityCheck(plan *types.DeletionPlan) string {
+	var shared []types.ResourceUsage
+	for _, resource := range plan.ResourcesToDelete {
+		if resource.IsShared(plan.Worker.Name) {
+			shared = append(shared, resource)
+		}
+	}
+
+	if len(shared) == 0 {
+		return RenderSuccess(fmt.Sprintf("%s is exclusive: no resource is used by another worker", plan.Worker.Name))
+	}
+
+	var b strings.Builder
+	b.WriteString(styles.Section.Render(fmt.Sprintf("%d shared resource(s) on %s:", len(shared), plan.Worker.Name)))
+	b.WriteString("\n")
+	for _, resource := range shared {
+		b.WriteString(fmt.Sprintf("  %s %s %s",
+			getRiskIndicator(resource.RiskLevel),
+			styles.FormatResourceType(string(resource.ResourceType)),
+			resource.ResourceName))
+		if others := getOtherWorkers(resource.UsedBy, plan.Worker.Name); len(others) > 0 {
+			b.WriteString(fmt.Sprintf(" %s", styles.Warning.Render(fmt.Sprintf("(used by %s)", strings.Join(others, ", ")))))
+		} else {
+			b.WriteString(fmt.Sprintf(" %s", styles.Muted.Render("(a worker that could not be checked may use it)")))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// RenderNonEmptyDatabases renders the confirmation warning for D1 databases
+// that still have tables
+func RenderNonEmptyDatabases(databases []types.ResourceUsage) string {
+	var b strings.Builder
+	b.WriteString(RenderError("These D1 databases still hold data, it will be lost:"))
+	b.WriteString("\n")
+	for _, database := range databases {
+		b.WriteString(fmt.Sprintf("  • %s %s\n", database.ResourceName,
+			styles.Muted.Render(fmt.Sprintf("(%s)", formatTableCount(*database.TableCount)))))
+	}
+	return b.String()
+}
+
+// RenderDangerResources renders the confirmation warning for the plan's
+// danger resources, with the workers that bind or consume each
+func RenderDangerResources(plan *types.DeletionPlan) string {
+	var b strings.Builder
+	b.WriteString(RenderError("These resources are widely shared or hold data:"))
+	b.WriteString("\n")
+	for _, resource := range plan.ResourcesToDelete {
+		if resource.RiskLevel != types.RiskLevelDanger {
+			continue
+		}
+
+		var reasons []string
+		if others := getOtherWorkers(resource.UsedBy, plan.Worker.Name); len(others) > 0 {
+			reasons = append(reasons, "used by "+summarizeWorkers(others))
+		}
+		if consumers := getOtherWorkers(resource.Consumers, plan.Worker.Name); len(consumers) > 0 {
+			reasons = append(reasons, "consumed by "+summarizeWorkers(consumers))
+		}
+		if resource.StorageBytes != nil && *resource.StorageBytes > 0 {
+			reasons = append(reasons, "holds "+formatBytes(*resource.StorageBytes))
+		}
+		b.WriteString(fmt.Sprintf("  • %s %s %s\n", styles.FormatResourceType(string(resource.ResourceType)), resource.ResourceName,
+			styles.Muted.Render(fmt.Sprintf("(%s)", strings.Join(reasons, "; ")))))
+	}
 	return b.String()
 }
 
+// RenderPlanDiff renders the changes between a saved plan and the current one
+func RenderPlanDiff(d diff.PlanDiff) string {
+	var b strings.Builder
+	b.WriteString(styles.Section.Render(fmt.Sprintf("Plan changes for %s:", d.Worker)))
+	b.WriteString("\n")
+
+	for _, resource := range d.Removed {
+		b.WriteString(styles.Error.Render("  - " + diff.FormatResource(resource)))
+		b.WriteString(" " + styles.Muted.Render("(already gone or excluded)") + "\n")
+	}
+	for _, resource := range d.Added {
+		b.WriteString(styles.Success.Render("  + " + diff.FormatResource(resource)))
+		b.WriteString(" " + styles.Muted.Render("(would be newly deleted)") + "\n")
+	}
+	for _, resource := range d.Unchanged {
+		b.WriteString(styles.Muted.Render("    "+diff.FormatResource(resource)) + "\n")
+	}
+
+	if !d.HasChanges() {
+		b.WriteString(RenderSuccess("No changes since the previous plan"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(fmt.Sprintf("\n%d added, %d removed, %d unchanged\n", len(d.Added), len(d.Removed), len(d.Unchanged)))
+	}
+	return b.String()
+}
+
+// summarizeWorkers names the first few workers and counts the rest
+func summarizeWorkers(workers []string) string {
+	if len(workers) <= sharingWorkersShown {
+		return strings.Join(workers, ", ")
+	}
+	return fmt.Sprintf("%s +%d more", strings.Join(workers[:sharingWorkersShown], ", "), len(workers)-sharingWorkersShown)
+}
+
+// RenderWorkerRoutes renders the zone routes attached to a worker
+func RenderWorkerRoutes(routes []types.WorkerRoute) string {
+	var b strings.Builder
+
+	b.WriteString(styles.Section.Render(fmt.Sprintf("Routes (%d):", len(routes))))
+	b.WriteString("\n")
+
+	for _, route := range routes {
+		// Routes in zones we couldn't resolve are likely outside this account
+		if route.ZoneName == "" {
+			b.WriteString(fmt.Sprintf("  %s\n", styles.Warning.Render(fmt.Sprintf("%s → %s", route.ZoneID, route.Pattern))))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s → %s\n", styles.Info.Render(route.ZoneName), route.Pattern))
+	}
+
+	return b.String()
+}
+
+// RenderAnalysisEstimate renders the expected duration of a dependency analysis
+func RenderAnalysisEstimate(workerCount int, estimate time.Duration) string {
+	return styles.Info.Render(fmt.Sprintf("Estimated analysis time: %s for %d workers.", FormatApproxDuration(estimate), workerCount))
+}
+
+// FormatApproxDuration formats a duration as a rough human-readable estimate
+func FormatApproxDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "under a second"
+	case d < time.Minute:
+		seconds := int(d.Round(time.Second).Seconds())
+		if seconds == 1 {
+			return "~1 second"
+		}
+		return fmt.Sprintf("~%d seconds", seconds)
+	default:
+		minutes := int(d.Round(time.Minute).Minutes())
+		if minutes == 1 {
+			return "~1 minute"
+		}
+		return fmt.Sprintf("~%d minutes", minutes)
+	}
+}
+
 // RenderProgress renders a progress message
 func RenderProgress(message string) string {
 	return styles.Info.Render(fmt.Sprintf("⏳ %s...", message))
@@ -119,19 +786,42 @@ func RenderWarning(message string) string {
 	return styles.Warning.Render(fmt.Sprintf("⚠️  %s", message))
 }
 
+// RenderHighlight renders emphasised text such as a name the user must type
+func RenderHighlight(text string) string {
+	return styles.Highlight.Render(text)
+}
+
+// RenderMuted renders a de-emphasised hint message
+func RenderMuted(message string) string {
+	return styles.Muted.Render(message)
+}
+
 // RenderDeletionResult renders the deletion result
 func RenderDeletionResult(result *types.DeletionResult) string {
 	var b strings.Builder
 
-	if result.Success {
+	switch {
+	case result.SkippedReason != "":
+		b.WriteString(styles.WarningBox.Render(buildSkippedContent(result)))
+	case result.Success:
 		b.WriteString(styles.SuccessBox.Render(buildSuccessContent(result)))
-	} else {
+	default:
 		b.WriteString(styles.DangerBox.Render(buildErrorContent(result)))
 	}
 
 	return b.String()
 }
 
+func buildSkippedContent(result *types.DeletionResult) string {
+	var b strings.Builder
+
+	b.WriteString(styles.Warning.Render("⚠️  Deletion Skipped"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("The %s, nothing was deleted\n", result.SkippedReason))
+
+	return b.String()
+}
+
 func buildSuccessContent(result *types.DeletionResult) string {
 	var b strings.Builder
 
@@ -140,6 +830,20 @@ func buildSuccessContent(result *types.DeletionResult) string {
 
 	if result.WorkerDeleted {
 		b.WriteString("✓ Worker script deleted\n")
+	} else if result.WorkerPreserved {
+		b.WriteString("⊗ Worker script preserved\n")
+	}
+
+	if len(result.RoutesDeleted) > 0 {
+		b.WriteString(fmt.Sprintf("✓ %d route(s) deleted\n", len(result.RoutesDeleted)))
+	}
+
+	if len(result.SecretsDeleted) > 0 {
+		b.WriteString(fmt.Sprintf("✓ %d secret(s) deleted\n", len(result.SecretsDeleted)))
+	}
+
+	if len(result.CronsCleared) > 0 {
+		b.WriteString(fmt.Sprintf("✓ %d cron trigger(s) removed\n", len(result.CronsCleared)))
 	}
 
 	if len(result.ResourcesDeleted) > 0 {
@@ -150,21 +854,47 @@ func buildSuccessContent(result *types.DeletionResult) string {
 		b.WriteString(fmt.Sprintf("⊗ %d resource(s) preserved (shared)\n", len(result.ResourcesSkipped)))
 	}
 
+	if d := result.Duration(); d > 0 {
+		b.WriteString(styles.Muted.Render(fmt.Sprintf("Completed in %s", d.Round(time.Millisecond))))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(renderSnapshots(result.Snapshots))
+	b.WriteString(renderNotices(result.Notices))
+
 	return b.String()
 }
 
 func buildErrorContent(result *types.DeletionResult) string {
 	var b strings.Builder
 
-	b.WriteString(styles.Error.Render("✗ Deletion Failed"))
+	if result.PartialFailure {
+		b.WriteString(styles.Error.Render("✗ Deletion Partially Failed"))
+	} else {
+		b.WriteString(styles.Error.Render("✗ Deletion Failed"))
+	}
 	b.WriteString("\n\n")
 
 	if result.WorkerDeleted {
 		b.WriteString("✓ Worker script deleted\n")
+	} else if result.WorkerPreserved {
+		b.WriteString("⊗ Worker script preserved\n")
 	} else {
 		b.WriteString("✗ Worker script not deleted\n")
 	}
 
+	if len(result.RoutesDeleted) > 0 {
+		b.WriteString(fmt.Sprintf("✓ %d route(s) deleted\n", len(result.RoutesDeleted)))
+	}
+
+	if len(result.SecretsDeleted) > 0 {
+		b.WriteString(fmt.Sprintf("✓ %d secret(s) deleted\n", len(result.SecretsDeleted)))
+	}
+
+	if len(result.CronsCleared) > 0 {
+		b.WriteString(fmt.Sprintf("✓ %d cron trigger(s) removed\n", len(result.CronsCleared)))
+	}
+
 	if len(result.ResourcesDeleted) > 0 {
 		b.WriteString(fmt.Sprintf("✓ %d resource(s) deleted\n", len(result.ResourcesDeleted)))
 	}
@@ -173,6 +903,9 @@ func buildErrorContent(result *types.DeletionResult) string {
 		b.WriteString(fmt.Sprintf("⊗ %d resource(s) skipped\n", len(result.ResourcesSkipped)))
 	}
 
+	b.WriteString(renderSnapshots(result.Snapshots))
+	b.WriteString(renderNotices(result.Notices))
+
 	if len(result.Errors) > 0 {
 		b.WriteString("\nErrors:\n")
 		for _, err := range result.Errors {
@@ -183,16 +916,250 @@ func buildErrorContent(result *types.DeletionResult) string {
 	return b.String()
 }
 
+// renderSnapshots lists the snapshot files written before deletion
+func renderSnapshots(snapshots []string) string {
+	if len(snapshots) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nSnapshots:\n")
+	for _, path := range snapshots {
+		b.WriteString(fmt.Sprintf("  • %s\n", path))
+	}
+	return b.String()
+}
+
+// RenderBulkSummary renders the per-worker outcomes of a bulk deletion
+func RenderBulkSummary(outcomes []types.WorkerOutcome) string {
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("Bulk Deletion Summary"))
+	b.WriteString("\n\n")
+
+	for _, outcome := range outcomes {
+		switch outcome.Status {
+		case types.OutcomeDeleted:
+			resources := 0
+			if outcome.Result != nil {
+				resources = len(outcome.Result.ResourcesDeleted)
+			}
+			b.WriteString(fmt.Sprintf("%s: %s\n", outcome.WorkerName,
+				styles.Success.Render(fmt.Sprintf("✓ deleted (%d resources)", resources))))
+		case types.OutcomePlanned:
+			resources := 0
+			if outcome.Plan != nil {
+				resources = outcome.Plan.Summary().TotalResources
+			}
+			b.WriteString(fmt.Sprintf("%s: %s\n", outcome.WorkerName,
+				styles.Muted.Render(fmt.Sprintf("planned (%d resources)", resources))))
+		case types.OutcomeSkipped:
+			reason := "guard"
+			if outcome.Result != nil {
+				reason = outcome.Result.SkippedReason
+			}
+			b.WriteString(fmt.Sprintf("%s: %s\n", outcome.WorkerName, styles.Warning.Render(fmt.Sprintf("⊗ skipped (%s)", reason))))
+		case types.OutcomeAborted:
+			reason := "user"
+			if outcome.Err != nil {
+				reason = outcome.Err.Error()
+			}
+			b.WriteString(fmt.Sprintf("%s: %s\n", outcome.WorkerName, styles.Muted.Render(fmt.Sprintf("⊗ aborted (%s)", reason))))
+		default:
+			reason := "errors"
+			if outcome.Err != nil {
+				reason = outcome.Err.Error()
+			} else if outcome.Result != nil && len(outcome.Result.Errors) > 0 {
+				reason = fmt.Sprintf("%d error(s)", len(outcome.Result.Errors))
+			}
+			b.WriteString(fmt.Sprintf("%s: %s\n", outcome.WorkerName,
+				styles.Error.Render(fmt.Sprintf("✗ failed (%s)", reason))))
+		}
+	}
+
+	return styles.Box.Render(b.String())
+}
+
 // Helper functions
 
-func groupResourcesByType(resources []types.ResourceUsage) map[types.BindingType][]types.ResourceUsage {
-	grouped := make(map[types.BindingType][]types.ResourceUsage)
-	for _, resource := range resources {
-		grouped[resource.ResourceType] = append(grouped[resource.ResourceType], resource)
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+// formatKeyCount describes a KV namespace's key count, marking counts that
+// stopped at the limit
+func formatKeyCount(n int) string {
+	if n >= types.MaxKVKeyCount {
+		return fmt.Sprintf("%d+ keys", types.MaxKVKeyCount)
+	}
+	if n == 1 {
+		return "1 key"
+	}
+	return fmt.Sprintf("%d keys", n)
+}
+
+// formatStorageEstimate describes the data counted in a plan's R2 buckets
+// and KV namespaces
+func formatStorageEstimate(e types.StorageEstimate) string {
+	var parts []string
+	if e.R2Bytes > 0 || e.R2Objects > 0 {
+		parts = append(parts, fmt.Sprintf("%s in %s (R2)", formatBytes(e.R2Bytes), formatObjects(e.R2Objects)))
+	}
+	if e.KVKeys > 0 {
+		keys := formatKeyCount(e.KVKeys)
+		if e.KVKeysCapped {
+			// A capped namespace was counted only up to MaxKVKeyCount
+			keys = fmt.Sprintf("%d+ keys", e.KVKeys)
+		}
+		parts = append(parts, keys+" (KV)")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatEstimate rounds an estimated duration up to whole seconds
+func formatEstimate(d time.Duration) string {
+	return (d + time.Second - 1).Truncate(time.Second).String()
+}
+
+// formatLastActive describes when a worker last served a request and how
+// many it served
+func formatLastActive(metrics *types.WorkerMetrics) string {
+	if metrics.LastRequestAt.IsZero() {
+		return "no recent requests"
+	}
+	return fmt.Sprintf("%s (%s requests total)", formatAgo(metrics.LastRequestAt), formatThousands(metrics.RequestCount))
+}
+
+// formatAgo describes how long ago t was, in minutes, hours or days
+func formatAgo(t time.Time) string {
+	ago := time.Since(t)
+	switch {
+	case ago < time.Minute:
+		return "just now"
+	case ago < time.Hour:
+		return pluralAgo(int(ago/time.Minute), "minute")
+	case ago < 24*time.Hour:
+		return pluralAgo(int(ago/time.Hour), "hour")
+	default:
+		return pluralAgo(int(ago/(24*time.Hour)), "day")
+	}
+}
+
+func pluralAgo(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s ago", unit)
+	}
+	return fmt.Sprintf("%d %ss ago", n, unit)
+}
+
+// formatThousands writes a count with comma thousands separators
+func formatThousands(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// formatObjects describes a number of R2 objects
+func formatObjects(n int) string {
+	if n == 1 {
+		return "1 object"
+	}
+	return fmt.Sprintf("%d objects", n)
+}
+
+// formatMessages describes a number of queue messages
+func formatMessages(n int) string {
+	if n == 1 {
+		return "1 message"
+	}
+	return fmt.Sprintf("%d messages", n)
+}
+
+// formatTableCount describes a D1 database's table count
+func formatTableCount(n int) string {
+	switch n {
+	case 0:
+		return "empty"
+	case 1:
+		return "1 table"
+	default:
+		return fmt.Sprintf("%d tables", n)
+	}
+}
+
+// formatBytes formats a size in bytes as B, KB, MB, GB or TB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	suffix := "B"
+	for _, s := range []string{"KB", "MB", "GB", "TB"} {
+		if value < unit {
+			break
+		}
+		value /= unit
+		suffix = s
+	}
+
+	// Whole numbers read better for the small sizes most scripts have
+	if value >= 10 {
+		return fmt.Sprintf("%.0f %s", value, suffix)
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+func renderNotices(notices []string) string {
+	if len(notices) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(styles.Warning.Render("Manual action required:"))
+	b.WriteString("\n")
+	for _, notice := range notices {
+		b.WriteString(fmt.Sprintf("  • %s\n", notice))
+	}
+	return b.String()
+}
+
+// groupResourcesByCategory groups resource indexes by category, then by type
+func groupResourcesByCategory(resources []types.ResourceUsage) map[string]map[types.BindingType][]int {
+	grouped := make(map[string]map[types.BindingType][]int)
+	for i, resource := range resources {
+		category := resource.ResourceType.Category()
+		if grouped[category] == nil {
+			grouped[category] = make(map[types.BindingType][]int)
+		}
+		grouped[category][resource.ResourceType] = append(grouped[category][resource.ResourceType], i)
 	}
 	return grouped
 }
 
+func sortedResourceTypes(grouped map[types.BindingType][]int) []types.BindingType {
+	resourceTypes := make([]types.BindingType, 0, len(grouped))
+	for resourceType := range grouped {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Slice(resourceTypes, func(i, j int) bool {
+		return resourceTypes[i] < resourceTypes[j]
+	})
+	return resourceTypes
+}
+
 func getRiskIndicator(level types.RiskLevel) string {
 	switch level {
 	case types.RiskLevelSafe:
@@ -201,6 +1168,8 @@ func getRiskIndicator(level types.RiskLevel) string {
 		return styles.RiskIndicator("caution")
 	case types.RiskLevelDanger:
 		return styles.RiskIndicator("danger")
+	case types.RiskLevelUnknown:
+		return styles.RiskIndicator("unknown")
 	default:
 		return styles.RiskIndicator("")
 	}
@@ -215,13 +1184,3 @@ func getOtherWorkers(usedBy []string, currentWorker string) []string {
 	}
 	return others
 }
-
-func countSharedResources(resources []types.ResourceUsage) int {
-	count := 0
-	for _, resource := range resources {
-		if resource.RiskLevel != types.RiskLevelSafe {
-			count++
-		}
-	}
-	return count
-}
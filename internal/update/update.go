@@ -0,0 +1,148 @@
+// Package update checks GitHub for newer releases of cf-purge-worker
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	releasesURL = "https://api.github.com/repos/MattieTK/cf-purge-worker/releases/latest"
+	cacheDir    = ".config/cf-purge-worker"
+	cacheFile   = "update-check.json"
+	// CacheTTL is how long a check result is reused before GitHub is asked again
+	CacheTTL = 24 * time.Hour
+)
+
+// Result is the outcome of a release check, as stored in the cache
+type Result struct {
+	Latest    string    `json:"latest"`
+	URL       string    `json:"url"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// NewerThan reports whether the latest release is newer than current
+func (r *Result) NewerThan(current string) bool {
+	return compareVersions(r.Latest, current) > 0
+}
+
+// DefaultCachePath returns the cache file location in the config directory
+func DefaultCachePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, cacheDir, cacheFile)
+}
+
+// Check returns the latest release, reusing the result cached at cachePath
+// when it is younger than CacheTTL. A fresh result is written back to the
+// cache; failing to write it is not an error.
+func Check(ctx context.Context, cachePath string) (*Result, error) {
+	if cached, err := readCache(cachePath); err == nil && time.Since(cached.CheckedAt) < CacheTTL {
+		return cached, nil
+	}
+
+	result, err := fetchLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = writeCache(cachePath, result)
+	return result, nil
+}
+
+// fetchLatest asks the GitHub Releases API for the latest release
+func fetchLatest(ctx context.Context) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release check request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for new releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for new releases: GitHub returned %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse latest release: %w", err)
+	}
+
+	return &Result{
+		Latest:    strings.TrimPrefix(release.TagName, "v"),
+		URL:       release.HTMLURL,
+		CheckedAt: time.Now(),
+	}, nil
+}
+
+func readCache(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func writeCache(path string, result *Result) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// compareVersions compares two dotted versions such as 1.2.3, ignoring a
+// leading v and any pre-release suffix. It returns -1, 0 or 1.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x > y:
+			return 1
+		case x < y:
+			return -1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(field)
+		parts = append(parts, n)
+	}
+	return parts
+}
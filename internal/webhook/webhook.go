@@ -0,0 +1,55 @@
+// Package webhook posts deletion results to a URL, for Slack, PagerDuty or
+// custom integrations
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+const (
+	// SecretHeader carries the shared secret so the receiver can authenticate the call
+	SecretHeader = "X-CF-Purge-Worker-Secret"
+	// SecretEnvVar supplies the secret when --webhook-secret isn't given
+	SecretEnvVar = "PURGE_WORKER_WEBHOOK_SECRET"
+	// Timeout bounds the call so a slow receiver can't hold up the run
+	Timeout = 10 * time.Second
+)
+
+// Send POSTs result as JSON to url. secret is sent in SecretHeader when set.
+// A response outside 2xx is returned as an error.
+func Send(ctx context.Context, url, secret string, result *types.DeletionResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(SecretHeader, secret)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
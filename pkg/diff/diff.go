@@ -0,0 +1,84 @@
+// Package diff compares two deletion plans for the same worker
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// PlanDiff lists how the resources to delete changed between two plans
+type PlanDiff struct {
+	Worker    string                `json:"worker"`
+	Added     []types.ResourceUsage `json:"added"`     // Only in the new plan, would be newly deleted
+	Removed   []types.ResourceUsage `json:"removed"`   // Only in the old plan, already gone or now excluded
+	Unchanged []types.ResourceUsage `json:"unchanged"` // In both plans
+}
+
+// DiffPlans compares the resources to delete in two plans. Resources are
+// matched by type and ID, so a renamed resource counts as unchanged.
+func DiffPlans(old, new *types.DeletionPlan) PlanDiff {
+	d := PlanDiff{Worker: new.Worker.Name}
+
+	inOld := make(map[string]bool, len(old.ResourcesToDelete))
+	for _, resource := range old.ResourcesToDelete {
+		inOld[resourceKey(resource)] = true
+	}
+
+	inNew := make(map[string]bool, len(new.ResourcesToDelete))
+	for _, resource := range new.ResourcesToDelete {
+		key := resourceKey(resource)
+		inNew[key] = true
+		if inOld[key] {
+			d.Unchanged = append(d.Unchanged, resource)
+		} else {
+			d.Added = append(d.Added, resource)
+		}
+	}
+
+	for _, resource := range old.ResourcesToDelete {
+		if !inNew[resourceKey(resource)] {
+			d.Removed = append(d.Removed, resource)
+		}
+	}
+
+	return d
+}
+
+// HasChanges reports whether any resource was added or removed
+func (d PlanDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+// Unified renders the diff in unified-diff style, one resource per line
+func (d PlanDiff) Unified() string {
+	var b strings.Builder
+
+	b.WriteString("--- previous plan\n")
+	b.WriteString("+++ current plan\n")
+	b.WriteString(fmt.Sprintf("@@ %s: +%d -%d =%d @@\n", d.Worker, len(d.Added), len(d.Removed), len(d.Unchanged)))
+	for _, resource := range d.Removed {
+		b.WriteString("-" + FormatResource(resource) + "\n")
+	}
+	for _, resource := range d.Added {
+		b.WriteString("+" + FormatResource(resource) + "\n")
+	}
+	for _, resource := range d.Unchanged {
+		b.WriteString(" " + FormatResource(resource) + "\n")
+	}
+
+	return b.String()
+}
+
+// FormatResource describes a resource on a single diff line
+func FormatResource(resource types.ResourceUsage) string {
+	if resource.ResourceName == "" || resource.ResourceName == resource.ResourceID {
+		return fmt.Sprintf("%s %s", resource.ResourceType, resource.ResourceID)
+	}
+	return fmt.Sprintf("%s %s (%s)", resource.ResourceType, resource.ResourceName, resource.ResourceID)
+}
+
+func resourceKey(resource types.ResourceUsage) string {
+	return fmt.Sprintf("%s:%s", resource.ResourceType, resource.ResourceID)
+}
@@ -0,0 +1,54 @@
+// Package errors defines typed errors for the failures callers need to tell
+// apart, so they can be matched with errors.As instead of by message
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkerNotFoundError is returned when the requested worker does not exist in
+// the account
+type WorkerNotFoundError struct {
+	Name string
+}
+
+func (e *WorkerNotFoundError) Error() string {
+	return fmt.Sprintf("worker not found: %s", e.Name)
+}
+
+// AuthError is returned when there is no usable API token or Cloudflare
+// rejects it
+type AuthError struct {
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+// RateLimitError is returned when the API is still rate limiting requests
+// after every retry. RetryAfter is how long the API asked to wait.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited by the Cloudflare API, retry after %s", e.RetryAfter)
+}
+
+// PartialDeletionError is returned when a deletion failed after part of the
+// plan was already deleted
+type PartialDeletionError struct {
+	Deleted []string
+	Failed  []string
+}
+
+func (e *PartialDeletionError) Error() string {
+	msg := "partial failure: already deleted " + strings.Join(e.Deleted, ", ")
+	if len(e.Failed) > 0 {
+		msg += "; failed " + strings.Join(e.Failed, ", ")
+	}
+	return msg
+}
@@ -0,0 +1,17 @@
+// Package estimates holds the typical Cloudflare API latencies used to
+// estimate how long a deletion plan takes. They are variables so they can be
+// tuned as the API gets faster or slower.
+package estimates
+
+import "time"
+
+var (
+	WorkerDelete   = time.Second            // Deleting the worker script
+	CronClear      = 300 * time.Millisecond // Clearing the worker's cron triggers
+	RouteDelete    = 300 * time.Millisecond // Deleting one zone route
+	SecretDelete   = 300 * time.Millisecond // Deleting one secret from a kept worker
+	KVDelete       = 500 * time.Millisecond // Deleting a KV namespace
+	R2Delete       = 2 * time.Second        // Emptying and deleting an R2 bucket
+	D1Delete       = 300 * time.Millisecond // Deleting a D1 database
+	ResourceDelete = 500 * time.Millisecond // Deleting any other resource
+)
@@ -0,0 +1,91 @@
+// Package exitcodes defines the process exit codes reported to callers such
+// as CI pipelines, so they can tell why a run failed
+package exitcodes
+
+import (
+	"errors"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+const (
+	Success         = 0 // Everything in the plan was deleted (or nothing needed doing)
+	GeneralError    = 1 // Any failure without a more specific code
+	WorkerNotFound  = 2 // The target worker does not exist in the account
+	AuthFailure     = 3 // No usable API token, or the token was rejected
+	PartialDeletion = 4 // The worker was deleted but some resources failed
+	UserCancelled   = 5 // The user declined a confirmation prompt
+	RateLimited     = 6 // The API kept rate limiting requests after every retry
+
+	// WorkerProtected shares the not found code, a protected worker is not
+	// available for deletion
+	WorkerProtected = WorkerNotFound
+
+	// SharedResources shares the partial deletion code, --assert-exclusive
+	// found resources that would survive the worker
+	SharedResources = PartialDeletion
+)
+
+// Error attaches an exit code to an error
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode wraps err so that it exits the process with code
+func WithCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// FromError returns the exit code carried by err, or GeneralError if it has none
+func FromError(err error) int {
+	if err == nil {
+		return Success
+	}
+
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return GeneralError
+}
+
+// ForResult returns the exit code for a deletion run. A nil result with no
+// error means the run was cancelled before deleting anything.
+func ForResult(result *types.DeletionResult, err error) int {
+	switch {
+	case err != nil:
+		return FromError(err)
+	case result == nil:
+		return UserCancelled
+	case result.Success:
+		return Success
+	case result.WorkerDeleted, result.PartialFailure:
+		return PartialDeletion
+	default:
+		return GeneralError
+	}
+}
+
+// ForStatus returns the exit code for the worst outcome of a batch
+func ForStatus(status types.OutcomeStatus) int {
+	switch status {
+	case types.OutcomeDeleted, types.OutcomePlanned, types.OutcomeSkipped:
+		return Success
+	case types.OutcomeAborted:
+		return UserCancelled
+	default:
+		return GeneralError
+	}
+}
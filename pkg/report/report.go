@@ -0,0 +1,205 @@
+// Package report renders a deletion result as a self-contained HTML page
+package report
+
+import (
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/types"
+)
+
+// reportTemplate uses the TUI's palette for the risk and outcome colours.
+// Everything is inline so the file can be archived or mailed on its own.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Deletion report: {{.Worker}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1F2937; }
+  h1 { color: #F38020; margin-bottom: 0.25rem; }
+  h2 { color: #1E3A8A; margin-top: 2rem; }
+  .muted { color: #9CA3AF; }
+  dl { display: grid; grid-template-columns: max-content auto; gap: 0.25rem 1rem; }
+  dt { font-weight: 600; }
+  dd { margin: 0; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: 0.4rem 0.75rem; border-bottom: 1px solid #E5E7EB; }
+  th { background: #374151; color: #FFFFFF; }
+  .safe { color: #10B981; }
+  .caution { color: #F59E0B; }
+  .danger { color: #EF4444; }
+  .unknown { color: #9CA3AF; }
+  .deleted { color: #10B981; }
+  .skipped { color: #F59E0B; }
+  .kept { color: #9CA3AF; }
+  .status-ok { color: #10B981; font-weight: 600; }
+  .status-failed { color: #EF4444; font-weight: 600; }
+  ul.errors li { color: #EF4444; }
+</style>
+</head>
+<body>
+<h1>Deletion report: {{.Worker}}</h1>
+<p class="muted">Generated {{.Generated}}</p>
+
+<dl>
+  <dt>Status</dt>
+  <dd>{{if .Success}}<span class="status-ok">Succeeded</span>{{else}}<span class="status-failed">{{if .PartialFailure}}Partially failed{{else}}Failed{{end}}</span>{{end}}</dd>
+  <dt>Worker script</dt>
+  <dd>{{.WorkerOutcome}}</dd>
+  <dt>Started</dt>
+  <dd>{{.Started}}</dd>
+  <dt>Completed</dt>
+  <dd>{{.Completed}}</dd>
+  <dt>Duration</dt>
+  <dd>{{.Duration}}</dd>
+{{- if .SkippedReason}}
+  <dt>Skipped</dt>
+  <dd>{{.SkippedReason}}</dd>
+{{- end}}
+</dl>
+
+<h2>Resources ({{len .Resources}})</h2>
+{{- if .Resources}}
+<table>
+  <thead>
+    <tr><th>Resource</th><th>Type</th><th>Risk</th><th>Used by</th><th>Outcome</th></tr>
+  </thead>
+  <tbody>
+{{- range .Resources}}
+    <tr>
+      <td>{{.Name}}{{if ne .ID .Name}} <span class="muted">{{.ID}}</span>{{end}}</td>
+      <td>{{.Type}}</td>
+      <td class="{{.Risk}}">{{.Risk}}</td>
+      <td>{{.UsedBy}}</td>
+      <td class="{{.Outcome}}">{{.Outcome}}</td>
+    </tr>
+{{- end}}
+  </tbody>
+</table>
+{{- else}}
+<p class="muted">The worker had no resources to delete.</p>
+{{- end}}
+
+{{- if .Routes}}
+<h2>Routes deleted ({{len .Routes}})</h2>
+<ul>{{range .Routes}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+
+{{- if .Crons}}
+<h2>Cron triggers cleared ({{len .Crons}})</h2>
+<ul>{{range .Crons}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{- end}}
+
+{{- if .Notices}}
+<h2>Notices</h2>
+<ul>{{range .Notices}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+
+{{- if .Errors}}
+<h2>Errors ({{len .Errors}})</h2>
+<ul class="errors">{{range .Errors}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// reportData is what the template renders
+type reportData struct {
+	Worker         string
+	Generated      string
+	Success        bool
+	PartialFailure bool
+	WorkerOutcome  string
+	Started        string
+	Completed      string
+	Duration       string
+	SkippedReason  string
+	Resources      []resourceRow
+	Routes         []string
+	Crons          []string
+	Notices        []string
+	Errors         []string
+}
+
+// resourceRow is one line of the resource table
+type resourceRow struct {
+	Name    string
+	ID      string
+	Type    string
+	Risk    string
+	UsedBy  string
+	Outcome string // deleted, skipped or kept; also the CSS class
+}
+
+// GenerateHTML renders the outcome of executing plan as an HTML page
+func GenerateHTML(result *types.DeletionResult, plan *types.DeletionPlan) string {
+	if result == nil {
+		result = &types.DeletionResult{}
+	}
+	if plan == nil {
+		plan = &types.DeletionPlan{}
+	}
+
+	data := reportData{
+		Worker:         plan.Worker.Name,
+		Generated:      formatTime(time.Now()),
+		Success:        result.Success,
+		PartialFailure: result.PartialFailure,
+		WorkerOutcome:  workerOutcome(result),
+		Started:        formatTime(result.StartedAt),
+		Completed:      formatTime(result.CompletedAt),
+		Duration:       result.Duration().Round(time.Millisecond).String(),
+		SkippedReason:  result.SkippedReason,
+		Routes:         result.RoutesDeleted,
+		Crons:          result.CronsCleared,
+		Notices:        result.Notices,
+	}
+	for _, err := range result.Errors {
+		data.Errors = append(data.Errors, err.Error())
+	}
+
+	for _, resource := range plan.ResourcesToDelete {
+		outcome := "kept"
+		switch {
+		case slices.Contains(result.ResourcesDeleted, resource.ResourceName):
+			outcome = "deleted"
+		case slices.Contains(result.ResourcesSkipped, resource.ResourceName):
+			outcome = "skipped"
+		}
+		data.Resources = append(data.Resources, resourceRow{
+			Name:    resource.ResourceName,
+			ID:      resource.ResourceID,
+			Type:    string(resource.ResourceType),
+			Risk:    resource.RiskLevel.String(),
+			UsedBy:  strings.Join(resource.UsedBy, ", "),
+			Outcome: outcome,
+		})
+	}
+
+	var b strings.Builder
+	if err := reportTemplate.Execute(&b, data); err != nil {
+		return "<p>failed to render report: " + template.HTMLEscapeString(err.Error()) + "</p>"
+	}
+	return b.String()
+}
+
+func workerOutcome(result *types.DeletionResult) string {
+	switch {
+	case result.WorkerDeleted:
+		return "deleted"
+	case result.WorkerPreserved:
+		return "preserved"
+	default:
+		return "not deleted"
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC1123)
+}
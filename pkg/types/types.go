@@ -1,94 +1,736 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/estimates"
+)
 
 // WorkerInfo contains details about a Cloudflare Worker
 type WorkerInfo struct {
-	Name         string
-	AccountID    string
-	CreatedOn    time.Time
-	ModifiedOn   time.Time
-	Bindings     []Binding
+	Name         string         `json:"name"`
+	AccountID    string         `json:"account_id"`
+	CreatedOn    time.Time      `json:"created_on"`
+	ModifiedOn   time.Time      `json:"modified_on"`
+	ScriptSize   int64          `json:"script_size,omitempty"` // Bytes, as reported by the script listing
+	UsageModel   string         `json:"usage_model,omitempty"` // bundled, unbound or standard
+	Bindings     []Binding      `json:"bindings"`
+	RecentErrors []TailEvent    `json:"recent_errors,omitempty"`
+	CronTriggers []CronTrigger  `json:"cron_triggers,omitempty"`
+	Tags         []string       `json:"tags,omitempty"`          // Set in the dashboard, from the script settings
+	SubdomainURL string         `json:"subdomain_url,omitempty"` // https://<worker>.<subdomain>.workers.dev
+	Metrics      *WorkerMetrics `json:"metrics,omitempty"`       // Recent traffic, nil when it couldn't be read
+	// UnknownBindings are the bindings of types this tool doesn't recognise,
+	// which may point at resources that need deleting by hand
+	UnknownBindings []Binding `json:"unknown_bindings,omitempty"`
+}
+
+// WorkerMetrics summarises the requests a worker served recently
+type WorkerMetrics struct {
+	RequestCount  int64     `json:"request_count"`
+	LastRequestAt time.Time `json:"last_request_at,omitempty"` // Zero when there were no requests
+}
+
+// ActiveWithin reports whether the worker served a request in the last d
+func (m *WorkerMetrics) ActiveWithin(d time.Duration) bool {
+	return m != nil && !m.LastRequestAt.IsZero() && time.Since(m.LastRequestAt) < d
+}
+
+// SecretNames returns the names of the worker's secret bindings. Their
+// values are never returned by the API.
+func (w *WorkerInfo) SecretNames() []string {
+	var names []string
+	for _, binding := range w.Bindings {
+		if binding.Type == BindingTypeSecret {
+			names = append(names, binding.Name)
+		}
+	}
+	return names
+}
+
+// CronTrigger is a scheduled invocation of a worker
+type CronTrigger struct {
+	Cron      string `json:"cron"`
+	CreatedOn string `json:"created_on,omitempty"`
+}
+
+// QueueDetails describes a queue, looked up by its name
+type QueueDetails struct {
+	ID        string   `json:"queue_id"`
+	Name      string   `json:"queue_name"`
+	Consumers []string `json:"consumers,omitempty"` // Worker scripts consuming the queue
+	Backlog   int      `json:"backlog,omitempty"`   // Messages waiting to be consumed
+}
+
+// DurableObjectNamespace is the storage namespace backing a Durable Object class
+type DurableObjectNamespace struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Script string `json:"script"`
+	Class  string `json:"class"`
+}
+
+// TailEvent is a sampled invocation event for a worker
+type TailEvent struct {
+	Timestamp        time.Time `json:"timestamp"`
+	Outcome          string    `json:"outcome"`
+	CPUTime          int64     `json:"cpu_time"`                    // Microseconds
+	ExceptionMessage string    `json:"exception_message,omitempty"` // Empty when the source doesn't report it
 }
 
 // Binding represents a resource binding in a worker
 type Binding struct {
-	Type         BindingType
-	Name         string
-	NamespaceID  string // For KV
-	BucketName   string // For R2
-	DatabaseID   string // For D1
-	DatabaseName string // For D1
-	ClassName    string // For Durable Objects
-	ScriptName   string // For Durable Objects and Service bindings
-	QueueName    string // For Queues
-	ConfigID     string // For Hyperdrive
-	IndexName    string // For Vectorize
+	Type          BindingType `json:"type"`
+	Name          string      `json:"name"`
+	NamespaceID   string      `json:"namespace_id,omitempty"`   // For KV
+	NamespaceName string      `json:"namespace_name,omitempty"` // For KV, set by inspect
+	BucketName    string      `json:"bucket_name,omitempty"`    // For R2
+	Jurisdiction  string      `json:"jurisdiction,omitempty"`   // For R2 (e.g. eu, fedramp), empty for the default jurisdiction
+	DatabaseID    string      `json:"database_id,omitempty"`    // For D1
+	DatabaseName  string      `json:"database_name,omitempty"`  // For D1
+	ClassName     string      `json:"class_name,omitempty"`     // For Durable Objects
+	ScriptName    string      `json:"script_name,omitempty"`    // For Durable Objects and Service bindings
+	QueueName     string      `json:"queue_name,omitempty"`     // For Queues
+	ConfigID      string      `json:"config_id,omitempty"`      // For Hyperdrive
+	IndexName     string      `json:"index_name,omitempty"`     // For Vectorize
+	CertificateID string      `json:"certificate_id,omitempty"` // For mTLS
+	DatasetName   string      `json:"dataset_name,omitempty"`   // For Analytics Engine
+	RawType       string      `json:"raw_type,omitempty"`       // For unknown types, the type the API reported
 }
 
 // BindingType represents the type of binding
 type BindingType string
 
 const (
-	BindingTypeKV             BindingType = "kv_namespace"
-	BindingTypeR2             BindingType = "r2_bucket"
-	BindingTypeD1             BindingType = "d1"
-	BindingTypeDurableObject  BindingType = "durable_object_namespace"
-	BindingTypeService        BindingType = "service"
-	BindingTypeQueue          BindingType = "queue"
-	BindingTypeHyperdrive     BindingType = "hyperdrive"
-	BindingTypeVectorize      BindingType = "vectorize"
-	BindingTypeEnvVar         BindingType = "plain_text"
-	BindingTypeSecret         BindingType = "secret_text"
-	BindingTypeMTLS           BindingType = "mtls_certificate"
+	BindingTypeKV                BindingType = "kv_namespace"
+	BindingTypeR2                BindingType = "r2_bucket"
+	BindingTypeD1                BindingType = "d1"
+	BindingTypeDurableObject     BindingType = "durable_object_namespace"
+	BindingTypeService           BindingType = "service"
+	BindingTypeQueue             BindingType = "queue"
+	BindingTypeHyperdrive        BindingType = "hyperdrive"
+	BindingTypeVectorize         BindingType = "vectorize"
+	BindingTypeEnvVar            BindingType = "plain_text"
+	BindingTypeSecret            BindingType = "secret_text"
+	BindingTypeMTLS              BindingType = "mtls_certificate"
+	BindingTypeDispatchNamespace BindingType = "dispatch_namespace"
+	BindingTypeVersionMetadata   BindingType = "version_metadata"
+	BindingTypeAI                BindingType = "ai"
+	BindingTypeBrowser           BindingType = "browser"
+	BindingTypeAnalyticsEngine   BindingType = "analytics_engine"
+	// BindingTypeUnknown marks a binding type this tool doesn't recognise,
+	// such as one Cloudflare added since. Binding.RawType holds the real type.
+	BindingTypeUnknown BindingType = "unknown"
 )
 
+// BindingTypeAlias maps short names accepted on the command line to binding types
+var BindingTypeAlias = map[string]BindingType{
+	"kv":               BindingTypeKV,
+	"r2":               BindingTypeR2,
+	"d1":               BindingTypeD1,
+	"do":               BindingTypeDurableObject,
+	"durable_object":   BindingTypeDurableObject,
+	"service":          BindingTypeService,
+	"queue":            BindingTypeQueue,
+	"hyperdrive":       BindingTypeHyperdrive,
+	"vectorize":        BindingTypeVectorize,
+	"mtls":             BindingTypeMTLS,
+	"dispatch":         BindingTypeDispatchNamespace,
+	"analytics_engine": BindingTypeAnalyticsEngine,
+}
+
+// ParseBindingType resolves an alias or full binding type name
+func ParseBindingType(name string) (BindingType, error) {
+	if t, ok := BindingTypeAlias[name]; ok {
+		return t, nil
+	}
+	for _, t := range BindingTypeAlias {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown resource type: %s", name)
+}
+
+// Binding categories used to group resources for display
+const (
+	CategoryStorage  = "Storage"
+	CategoryCompute  = "Compute"
+	CategoryPlatform = "Platform"
+	CategoryNetwork  = "Network"
+	CategoryConfig   = "Config"
+	CategoryOther    = "Other"
+)
+
+// Categories lists the binding categories in display order
+var Categories = []string{
+	CategoryStorage,
+	CategoryCompute,
+	CategoryPlatform,
+	CategoryNetwork,
+	CategoryConfig,
+	CategoryOther,
+}
+
+// Category returns the broad category a binding type belongs to
+func (t BindingType) Category() string {
+	switch t {
+	case BindingTypeKV, BindingTypeR2, BindingTypeD1, BindingTypeQueue:
+		return CategoryStorage
+	case BindingTypeDurableObject, BindingTypeService, BindingTypeDispatchNamespace:
+		return CategoryCompute
+	case BindingTypeAI, BindingTypeBrowser, BindingTypeAnalyticsEngine, BindingTypeHyperdrive, BindingTypeVectorize:
+		return CategoryPlatform
+	case BindingTypeMTLS:
+		return CategoryNetwork
+	case BindingTypeEnvVar, BindingTypeSecret, BindingTypeVersionMetadata:
+		return CategoryConfig
+	default:
+		return CategoryOther
+	}
+}
+
 // ResourceUsage tracks which workers use a specific resource
 type ResourceUsage struct {
-	ResourceID   string
-	ResourceType BindingType
-	ResourceName string
-	UsedBy       []string // Worker names
-	RiskLevel    RiskLevel
+	ResourceID   string      `json:"resource_id"`
+	ResourceType BindingType `json:"resource_type"`
+	ResourceName string      `json:"resource_name"`
+	Location     string      `json:"location,omitempty"`      // For R2 (location hint, e.g. WEUR)
+	Jurisdiction string      `json:"jurisdiction,omitempty"`  // For R2 (e.g. eu, fedramp), empty for the default jurisdiction
+	StorageBytes *int64      `json:"storage_bytes,omitempty"` // For R2, bytes stored in the bucket; nil when not counted
+	ObjectCount  int         `json:"object_count,omitempty"`  // For R2, objects stored in the bucket
+	KeyCount     *int        `json:"key_count,omitempty"`     // For KV, keys in the namespace (at most MaxKVKeyCount); nil when not counted
+	TableCount   *int        `json:"table_count,omitempty"`   // For D1, user tables in the database; nil when not counted
+	Tags         []string    `json:"tags,omitempty"`          // Dashboard tags on KV, R2 and D1 resources
+	Backlog      int         `json:"backlog,omitempty"`       // For Queues, messages waiting to be consumed
+	Consumers    []string    `json:"consumers,omitempty"`     // For Queues, worker scripts consuming the queue
+	UsedBy       []string    `json:"used_by"`                 // Worker names
+	RiskLevel    RiskLevel   `json:"risk_level"`
+	// NamespaceResolved is set on a Durable Object whose ResourceID is the
+	// ID of the storage namespace the worker owns, rather than its class name
+	NamespaceResolved bool `json:"namespace_resolved,omitempty"`
+}
+
+// MaxKVKeyCount is where key counting stops, so a huge namespace doesn't
+// take thousands of requests to count
+const MaxKVKeyCount = 10000
+
+// IsShared reports whether another worker uses the resource, or may use it
+// because the analysis could not read every worker. Unlike the risk level it
+// ignores the data a resource holds.
+func (r ResourceUsage) IsShared(workerName string) bool {
+	if r.RiskLevel == RiskLevelUnknown {
+		return true
+	}
+	for _, user := range r.UsedBy {
+		if user != workerName {
+			return true
+		}
+	}
+	return r.HasOtherConsumers(workerName)
+}
+
+// Users returns the workers that bind or consume the resource, each once
+func (r ResourceUsage) Users() []string {
+	users := slices.Clone(r.UsedBy)
+	for _, consumer := range r.Consumers {
+		if !slices.Contains(users, consumer) {
+			users = append(users, consumer)
+		}
+	}
+	return users
+}
+
+// HasOtherConsumers reports whether a worker other than workerName consumes
+// the queue
+func (r ResourceUsage) HasOtherConsumers(workerName string) bool {
+	for _, consumer := range r.Consumers {
+		if consumer != workerName {
+			return true
+		}
+	}
+	return false
 }
 
 // RiskLevel indicates the risk of deleting a resource
 type RiskLevel int
 
 const (
-	RiskLevelSafe     RiskLevel = iota // Exclusive to this worker
-	RiskLevelCaution                   // Used by 1-2 other workers
-	RiskLevelDanger                    // Used by 3+ workers
+	RiskLevelSafe    RiskLevel = iota // Exclusive to this worker
+	RiskLevelCaution                  // Used by 1-2 other workers
+	RiskLevelDanger                   // Used by 3+ workers, or an R2 bucket holding data
+
+	// RiskLevelUnknown marks resources that may be shared with a worker the
+	// analysis could not read
+	RiskLevelUnknown RiskLevel = -1
 )
 
+// String returns the lowercase name of the risk level
+func (r RiskLevel) String() string {
+	switch r {
+	case RiskLevelSafe:
+		return "safe"
+	case RiskLevelCaution:
+		return "caution"
+	case RiskLevelDanger:
+		return "danger"
+	case RiskLevelUnknown:
+		return "unknown"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the risk level by name
+func (r RiskLevel) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes a risk level from its name
+func (r *RiskLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "safe":
+		*r = RiskLevelSafe
+	case "caution":
+		*r = RiskLevelCaution
+	case "danger":
+		*r = RiskLevelDanger
+	case "unknown":
+		*r = RiskLevelUnknown
+	default:
+		return fmt.Errorf("unknown risk level: %s", text)
+	}
+	return nil
+}
+
+// WorkerRoute is a zone route that sends traffic to a worker
+type WorkerRoute struct {
+	ID       string `json:"id"`
+	Pattern  string `json:"pattern"`
+	ZoneID   string `json:"zone_id"`
+	ZoneName string `json:"zone_name,omitempty"` // Empty when the zone could not be resolved in this account
+}
+
 // DeletionPlan describes what will be deleted
 type DeletionPlan struct {
-	Worker            WorkerInfo
-	ResourcesToDelete []ResourceUsage
-	HasSharedResources bool
-	DeleteShared      bool
-	DeleteExclusiveOnly bool
+	Worker                 WorkerInfo      `json:"worker"`
+	ResourcesToDelete      []ResourceUsage `json:"resources_to_delete"`
+	ResourcesExcluded      []string        `json:"resources_excluded,omitempty"`        // Names of resources kept by --exclude or deselected
+	ResourcesSkippedByType []ResourceUsage `json:"resources_skipped_by_type,omitempty"` // Resources kept by --skip-type
+	ResourcesSkippedEmpty  []ResourceUsage `json:"resources_skipped_empty,omitempty"`   // Empty resources kept by --skip-empty-resources
+	IncludeTypes           []BindingType   `json:"include_types,omitempty"`             // Only these types are deleted when set
+	TagFilter              string          `json:"tag_filter,omitempty"`                // Only resources with this tag are deleted when set
+	RegionFilter           string          `json:"region_filter,omitempty"`             // Only R2 buckets in this jurisdiction are deleted when set
+	Routes                 []WorkerRoute   `json:"routes,omitempty"`
+	RouteLookupError       string          `json:"route_lookup_error,omitempty"` // Why Routes may be incomplete, --delete-routes is refused when set
+	HasSharedResources     bool            `json:"has_shared_resources"`
+	DeleteShared           bool            `json:"delete_shared"`
+	DeleteExclusiveOnly    bool            `json:"delete_exclusive_only"`
+	SkipWorkerDeletion     bool            `json:"skip_worker_deletion,omitempty"` // Keep the worker script, delete only resources
+	DeleteRoutes           bool            `json:"delete_routes,omitempty"`        // Delete the worker's zone routes before the worker
+	DeleteSecrets          bool            `json:"delete_secrets,omitempty"`       // Delete the secrets of a kept worker
+	EmptyR2Buckets         bool            `json:"empty_r2_buckets,omitempty"`     // Delete the objects in R2 buckets before the buckets
+	SkippedWorkers         []string        `json:"skipped_workers,omitempty"`      // Workers the dependency analysis could not read
+	AnalysisTruncated      bool            `json:"analysis_truncated,omitempty"`   // Dependency analysis stopped at --max-workers
+	AnalysisLimit          int             `json:"analysis_limit,omitempty"`       // Workers scanned when the analysis was truncated
+}
+
+// PlanSummary counts a plan's resources by risk level and type
+type PlanSummary struct {
+	WorkerName       string              `json:"worker_name"`
+	TotalResources   int                 `json:"total_resources"`
+	SafeResources    int                 `json:"safe_resources"`
+	CautionResources int                 `json:"caution_resources"`
+	DangerResources  int                 `json:"danger_resources"`
+	UnknownResources int                 `json:"unknown_resources"`
+	ByType           map[BindingType]int `json:"by_type"`
+	// TotalResourceStorageEstimate totals the data the resources hold, as
+	// far as the enrichment lookups counted it
+	TotalResourceStorageEstimate StorageEstimate `json:"total_resource_storage_estimate"`
+}
+
+// StorageEstimate totals the data held by R2 buckets and KV namespaces. It
+// is zero when enrichment was skipped.
+type StorageEstimate struct {
+	R2Bytes   int64 `json:"r2_bytes"`
+	R2Objects int   `json:"r2_objects"`
+	KVKeys    int   `json:"kv_keys"`
+	// KVKeysCapped is set when a namespace reached MaxKVKeyCount, so KVKeys
+	// is a lower bound
+	KVKeysCapped bool `json:"kv_keys_capped,omitempty"`
+}
+
+// IsZero reports whether no data was counted
+func (e StorageEstimate) IsZero() bool {
+	return e.R2Bytes == 0 && e.R2Objects == 0 && e.KVKeys == 0
+}
+
+// add counts the data held by an R2 bucket or KV namespace
+func (e *StorageEstimate) add(resource ResourceUsage) {
+	switch resource.ResourceType {
+	case BindingTypeR2:
+		if resource.StorageBytes != nil {
+			e.R2Bytes += *resource.StorageBytes
+		}
+		e.R2Objects += resource.ObjectCount
+	case BindingTypeKV:
+		if resource.KeyCount == nil {
+			return
+		}
+		e.KVKeys += *resource.KeyCount
+		if *resource.KeyCount >= MaxKVKeyCount {
+			e.KVKeysCapped = true
+		}
+	}
+}
+
+// SharedResources returns how many resources are known to be used by other workers
+func (s PlanSummary) SharedResources() int {
+	return s.CautionResources + s.DangerResources
+}
+
+// ErrInvalidPlan is returned by Validate for an inconsistent plan
+var ErrInvalidPlan = errors.New("invalid deletion plan")
+
+// Validate checks the plan is consistent before anything is deleted: the
+// worker is named, no resource is listed twice and every service binding names
+// its target. Service binding targets are other workers and are never deleted
+// with the plan, so a live target is left alone rather than rejected.
+func (p *DeletionPlan) Validate() error {
+	var violations []error
+	if p.Worker.Name == "" {
+		violations = append(violations, errors.New("worker name is empty"))
+	}
+
+	seen := make(map[string]bool)
+	for _, resource := range p.ResourcesToDelete {
+		key := fmt.Sprintf("%s:%s", resource.ResourceType, resource.ResourceID)
+		if seen[key] {
+			violations = append(violations, fmt.Errorf("%s %s is listed more than once", resource.ResourceType, resource.ResourceName))
+		}
+		seen[key] = true
+
+		if resource.ResourceType == BindingTypeService && resource.ResourceID == "" {
+			violations = append(violations, fmt.Errorf("service binding %s has no target worker", resource.ResourceName))
+		}
+	}
+
+	// Deleting the worker with only some of its routes would orphan the rest
+	if p.DeleteRoutes && p.RouteLookupError != "" {
+		violations = append(violations, fmt.Errorf("routes could not all be listed, not deleting them: %s", p.RouteLookupError))
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidPlan, errors.Join(violations...))
+	}
+	return nil
+}
+
+// Summary counts the resources to delete by risk level and type
+func (p *DeletionPlan) Summary() PlanSummary {
+	summary := PlanSummary{
+		WorkerName:     p.Worker.Name,
+		TotalResources: len(p.ResourcesToDelete),
+		ByType:         make(map[BindingType]int),
+	}
+	for _, resource := range p.ResourcesToDelete {
+		summary.ByType[resource.ResourceType]++
+		summary.TotalResourceStorageEstimate.add(resource)
+		switch resource.RiskLevel {
+		case RiskLevelSafe:
+			summary.SafeResources++
+		case RiskLevelCaution:
+			summary.CautionResources++
+		case RiskLevelDanger:
+			summary.DangerResources++
+		default:
+			summary.UnknownResources++
+		}
+	}
+	return summary
+}
+
+// MarshalJSON adds the plan summary to the encoded plan
+func (p DeletionPlan) MarshalJSON() ([]byte, error) {
+	type plan DeletionPlan
+	return json.Marshal(struct {
+		plan
+		Summary PlanSummary `json:"summary"`
+	}{plan(p), p.Summary()})
+}
+
+// HasDangerResources reports whether any resource to delete is used by 3+ other workers
+func (p *DeletionPlan) HasDangerResources() bool {
+	return p.Summary().DangerResources > 0
+}
+
+// HasCautionResources reports whether any resource to delete is used by 1-2
+// other workers, or may be shared with a worker the analysis skipped
+func (p *DeletionPlan) HasCautionResources() bool {
+	summary := p.Summary()
+	return summary.CautionResources > 0 || summary.UnknownResources > 0
+}
+
+// NonEmptyDatabases returns the D1 databases to delete that have tables, and
+// so hold data that deletion loses
+func (p *DeletionPlan) NonEmptyDatabases() []ResourceUsage {
+	var databases []ResourceUsage
+	for _, resource := range p.ResourcesToDelete {
+		if resource.TableCount != nil && *resource.TableCount > 0 {
+			databases = append(databases, resource)
+		}
+	}
+	return databases
+}
+
+// EstimatedDuration roughly estimates how long the plan takes to run, from
+// its resource counts and the typical latencies in pkg/estimates. Only
+// resources that trigger a delete request count: service bindings, Durable
+// Objects of another worker and resources deleted by hand cost nothing.
+func (p *DeletionPlan) EstimatedDuration() time.Duration {
+	var d time.Duration
+	if !p.SkipWorkerDeletion {
+		d += estimates.WorkerDelete
+		if len(p.Worker.CronTriggers) > 0 {
+			d += estimates.CronClear
+		}
+	}
+	if p.DeleteRoutes {
+		d += time.Duration(len(p.Routes)) * estimates.RouteDelete
+	}
+	if p.DeleteSecrets {
+		d += time.Duration(len(p.Worker.SecretNames())) * estimates.SecretDelete
+	}
+
+	for _, resource := range p.ResourcesToDelete {
+		switch resource.ResourceType {
+		case BindingTypeKV:
+			d += estimates.KVDelete
+		case BindingTypeR2:
+			d += estimates.R2Delete
+		case BindingTypeD1:
+			d += estimates.D1Delete
+		case BindingTypeMTLS, BindingTypeAnalyticsEngine, BindingTypeService:
+		case BindingTypeDurableObject:
+			if resource.NamespaceResolved {
+				d += estimates.ResourceDelete
+			}
+		default:
+			d += estimates.ResourceDelete
+		}
+	}
+	return d
+}
+
+// NonEmptyBuckets returns the R2 buckets in the plan that hold data. R2
+// refuses to delete a bucket until it is empty.
+func (p *DeletionPlan) NonEmptyBuckets() []ResourceUsage {
+	var buckets []ResourceUsage
+	for _, resource := range p.ResourcesToDelete {
+		if resource.ResourceType == BindingTypeR2 && ((resource.StorageBytes != nil && *resource.StorageBytes > 0) || resource.ObjectCount > 0) {
+			buckets = append(buckets, resource)
+		}
+	}
+	return buckets
 }
 
 // DeletionResult tracks the outcome of a deletion operation
 type DeletionResult struct {
-	Success       bool
-	WorkerDeleted bool
-	ResourcesDeleted []string
-	ResourcesSkipped []string
-	Errors        []error
+	Success          bool      `json:"success"`
+	WorkerDeleted    bool      `json:"worker_deleted"`
+	WorkerPreserved  bool      `json:"worker_preserved,omitempty"` // Worker deletion was skipped on purpose
+	SkippedReason    string    `json:"skipped_reason,omitempty"`   // Why nothing was deleted, when a guard stopped the run
+	ResourcesDeleted []string  `json:"resources_deleted"`
+	ResourcesSkipped []string  `json:"resources_skipped"`
+	RoutesDeleted    []string  `json:"routes_deleted,omitempty"`  // Patterns of the deleted zone routes
+	SecretsDeleted   []string  `json:"secrets_deleted,omitempty"` // Names of the secrets deleted from a kept worker
+	CronsCleared     []string  `json:"crons_cleared,omitempty"`   // Cron expressions removed from the worker
+	Notices          []string  `json:"notices,omitempty"`         // Follow-up actions the user must take
+	Snapshots        []string  `json:"snapshots,omitempty"`       // Files holding the data of deleted KV namespaces and D1 databases
+	PartialFailure   bool      `json:"partial_failure,omitempty"` // Some things were deleted before a failure, the rest remain
+	Errors           []error   `json:"errors"`
+	StartedAt        time.Time `json:"started_at"`
+	CompletedAt      time.Time `json:"completed_at"`
+}
+
+// Duration returns how long the deletion took
+func (r DeletionResult) Duration() time.Duration {
+	if r.StartedAt.IsZero() || r.CompletedAt.IsZero() {
+		return 0
+	}
+	return r.CompletedAt.Sub(r.StartedAt)
+}
+
+// MarshalJSON encodes the result with errors as plain strings and the
+// duration in milliseconds
+func (r DeletionResult) MarshalJSON() ([]byte, error) {
+	type alias DeletionResult
+	errs := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err.Error())
+	}
+	return json.Marshal(struct {
+		alias
+		Errors     []string `json:"errors"`
+		DurationMS int64    `json:"duration_ms"`
+	}{alias: alias(r), Errors: errs, DurationMS: r.Duration().Milliseconds()})
+}
+
+// UnmarshalJSON decodes a result whose errors were encoded as strings
+func (r *DeletionResult) UnmarshalJSON(data []byte) error {
+	type alias DeletionResult
+	aux := struct {
+		*alias
+		Errors []string `json:"errors"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Errors = make([]error, 0, len(aux.Errors))
+	for _, msg := range aux.Errors {
+		r.Errors = append(r.Errors, errors.New(msg))
+	}
+	return nil
+}
+
+// OutcomeStatus describes how processing a single worker ended
+type OutcomeStatus string
+
+const (
+	OutcomeDeleted OutcomeStatus = "deleted"
+	OutcomePlanned OutcomeStatus = "planned" // Dry run, nothing was deleted
+	OutcomeSkipped OutcomeStatus = "skipped" // A guard such as --since kept the worker
+	OutcomeAborted OutcomeStatus = "aborted"
+	OutcomeFailed  OutcomeStatus = "failed"
+)
+
+// Severity orders outcomes from best (0) to worst
+func (s OutcomeStatus) Severity() int {
+	switch s {
+	case OutcomeDeleted, OutcomePlanned, OutcomeSkipped:
+		return 0
+	case OutcomeAborted:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// WorkerOutcome records the result of processing one worker in a batch
+type WorkerOutcome struct {
+	WorkerName string          `json:"worker"`
+	Status     OutcomeStatus   `json:"status"`
+	Plan       *DeletionPlan   `json:"plan,omitempty"`
+	Result     *DeletionResult `json:"result,omitempty"`
+	Err        error           `json:"-"`
+}
+
+// MarshalJSON encodes the outcome with its error as a plain string
+func (o WorkerOutcome) MarshalJSON() ([]byte, error) {
+	type alias WorkerOutcome
+	var errMsg string
+	if o.Err != nil {
+		errMsg = o.Err.Error()
+	}
+	return json.Marshal(struct {
+		alias
+		Error string `json:"error,omitempty"`
+	}{alias: alias(o), Error: errMsg})
+}
+
+// ErrSkippedAfterFailure marks batch workers not processed because of --fail-fast
+var ErrSkippedAfterFailure = errors.New("skipped after an earlier failure")
+
+// BatchDeletionResult aggregates the per-worker outcomes of a batch run
+type BatchDeletionResult struct {
+	Outcomes []WorkerOutcome
+}
+
+// Add records the outcome for one worker
+func (b *BatchDeletionResult) Add(outcome WorkerOutcome) {
+	b.Outcomes = append(b.Outcomes, outcome)
+}
+
+// WorstStatus returns the most severe outcome in the batch
+func (b *BatchDeletionResult) WorstStatus() OutcomeStatus {
+	worst := OutcomeDeleted
+	for _, outcome := range b.Outcomes {
+		if outcome.Status.Severity() > worst.Severity() {
+			worst = outcome.Status
+		}
+	}
+	return worst
+}
+
+// MarshalJSON encodes the batch as an array of outcomes
+func (b BatchDeletionResult) MarshalJSON() ([]byte, error) {
+	if b.Outcomes == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(b.Outcomes)
+}
+
+// TokenInfo describes the API token in use, without its secret value
+type TokenInfo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name,omitempty"`
+	Status      string    `json:"status"`
+	ExpiresOn   time.Time `json:"expires_on,omitempty"`
+	OwnerEmail  string    `json:"owner_email,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
 }
 
 // Config holds the application configuration
 type Config struct {
 	APIKey              string
+	NoKeychain          bool // Keep the token in the credentials file only
 	AccountID           string
 	DryRun              bool
 	Force               bool
 	ExclusiveOnly       bool
+	ForceDeleteShared   bool // Delete shared resources without the shared resource prompt
 	AutoYes             bool
 	Verbose             bool
 	Quiet               bool
 	JSONOutput          bool
+	Summary             bool
 	SkipDependencyCheck bool
+	NoEnrichment        bool
+	AnalysisConcurrency int
+	DeletionConcurrency int           // Resources deleted in parallel, after the worker script
+	MaxWorkers          int           // Scan at most this many workers during analysis (0 for all)
+	TagFilter           string        // Only delete resources carrying this tag
+	RegionFilter        string        // Only delete R2 buckets in this jurisdiction
+	SkipResourceTypes   []BindingType // Never delete resources of these types, shared or not
+	Exclude             []string      // Resource IDs or names never to delete
+	Include             []string      // Resource types (or aliases) to limit deletion to
+	RetryMax            int           // Retries for rate-limited API requests
+	RetryWaitMax        time.Duration // Longest wait before a single retry
+	Timeout             time.Duration // Abort the whole run after this long (0 for no limit)
+	WorkersFile         string        // File of worker names to delete in a batch ("-" for stdin)
+	NameRegex           string        // Only consider workers whose names match this regular expression
+	FailFast            bool          // Stop a batch at the first failed worker
+	OutputFile          string        // Append plan and result records to this audit file
+	SkipWorkerDeletion  bool          // Delete only the worker's resources, keep the script
+	Since               time.Time     // Skip workers modified after this time (zero for no limit)
+	InteractiveSelect   bool          // Pick the resources to delete from a checklist
+	DeleteRoutes        bool          // Delete the worker's zone routes too
+	DeleteSecrets       bool          // Delete the secrets of a worker kept by SkipWorkerDeletion
+	ProtectedWorkers    []string      // Worker names or glob patterns that must never be deleted
+	RequireTag          string        // Refuse to delete a worker without this tag
+	ManifestFile        string        // Record deleted resources here for the undo command
+	SnapshotDir         string        // Save KV and D1 data here before deleting it
+	SkipEmptyResources  bool          // Keep KV, D1 and R2 resources that hold no data
+	EmptyR2BeforeDelete bool          // Delete the objects in R2 buckets so the buckets can be deleted
+	WaitForPropagation  bool          // After deleting, wait until the worker is no longer served
+	PropagationTimeout  time.Duration // Longest wait for the deletion to propagate
+	NoUpdateCheck       bool          // Don't check GitHub for a newer release after a run
+	ReportFile          string        // Write an HTML report of the deletion here
+	Webhook             string        // POST each deletion result here as JSON
+	WebhookSecret       string        // Sent with the webhook call so the receiver can authenticate it
+	JSONLogFile         string        // Append a JSON line per analysis, resource deletion and result here
 }
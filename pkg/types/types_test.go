@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattietk/cf-purge-worker/pkg/estimates"
+)
+
+func TestEstimatedDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource ResourceUsage
+		want     time.Duration
+	}{
+		{"KV namespace", ResourceUsage{ResourceType: BindingTypeKV}, estimates.KVDelete},
+		{"queue", ResourceUsage{ResourceType: BindingTypeQueue}, estimates.ResourceDelete},
+		{"resolved Durable Object", ResourceUsage{ResourceType: BindingTypeDurableObject, NamespaceResolved: true}, estimates.ResourceDelete},
+		{"Durable Object of another worker", ResourceUsage{ResourceType: BindingTypeDurableObject}, 0},
+		{"service binding", ResourceUsage{ResourceType: BindingTypeService}, 0},
+		{"mTLS certificate", ResourceUsage{ResourceType: BindingTypeMTLS}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := DeletionPlan{SkipWorkerDeletion: true, ResourcesToDelete: []ResourceUsage{tt.resource}}
+			if got := plan.EstimatedDuration(); got != tt.want {
+				t.Errorf("EstimatedDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}